@@ -0,0 +1,167 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// SpecAnnotation keeps the v2 spec of a Namespaceconfig read or written as v1
+// when v1 cannot represent it, so that converting it back to v2 loses nothing
+const SpecAnnotation = "namespaceconfig.myoperator.io/v2-spec"
+
+// ConvertTo converts this Namespaceconfig to the Hub version (v2).
+func (src *Namespaceconfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v2.Namespaceconfig)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	base := v2.NamespaceconfigSpec{}
+	if spec, ok := dst.Annotations[SpecAnnotation]; ok {
+		if err := json.Unmarshal([]byte(spec), &base); err != nil {
+			return err
+		}
+		delete(dst.Annotations, SpecAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	dst.Spec = specToV2(&src.Spec, base)
+
+	dst.Status = v2.NamespaceconfigStatus{
+		NamespaceName:      src.Status.NamespaceName,
+		AppliedSize:        src.Status.AppliedSize,
+		EffectiveLimits:    src.Status.EffectiveLimits.DeepCopy(),
+		EffectiveQuota:     src.Status.EffectiveQuota.DeepCopy(),
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	for _, condition := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *condition.DeepCopy())
+	}
+	for _, step := range src.Status.Steps {
+		dst.Status.Steps = append(dst.Status.Steps, v2.ProvisioningStep{
+			Name:               step.Name,
+			State:              v2.StepState(step.State),
+			Message:            step.Message,
+			LastTransitionTime: step.LastTransitionTime,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version.
+func (dst *Namespaceconfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v2.Namespaceconfig)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = NamespaceconfigSpec{
+		Environment:        src.Spec.Environment,
+		Abbreviation:       src.Spec.Abbreviation,
+		NamespaceLimits:    src.Spec.Limits.DeepCopy(),
+		NamespaceQuota:     src.Spec.Quota.DeepCopy(),
+		NamespaceSize:      src.Spec.Size,
+		DeletionPolicy:     DeletionPolicy(src.Spec.DeletionPolicy),
+		DeletionProtection: src.Spec.DeletionProtection,
+	}
+	if len(src.Spec.Owners) > 0 {
+		dst.Spec.NamespaceOwner = src.Spec.Owners[0].Name
+	}
+	delete(dst.Annotations, SpecAnnotation)
+	if !equality.Semantic.DeepEqual(specToV2(&dst.Spec, v2.NamespaceconfigSpec{}), src.Spec) {
+		spec, err := json.Marshal(src.Spec)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[SpecAnnotation] = string(spec)
+	}
+
+	dst.Status = NamespaceconfigStatus{
+		NamespaceName:      src.Status.NamespaceName,
+		Status:             summary(src.Status.Conditions),
+		LastUpdateTime:     lastTransition(src.Status.Conditions),
+		EffectiveLimits:    src.Status.EffectiveLimits.DeepCopy(),
+		EffectiveQuota:     src.Status.EffectiveQuota.DeepCopy(),
+		AppliedSize:        src.Status.AppliedSize,
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	for _, condition := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *condition.DeepCopy())
+	}
+	for _, step := range src.Status.Steps {
+		dst.Status.Steps = append(dst.Status.Steps, ProvisioningStep{
+			Name:               step.Name,
+			State:              StepState(step.State),
+			Message:            step.Message,
+			LastTransitionTime: step.LastTransitionTime,
+		})
+	}
+	return nil
+}
+
+// specToV2 sets the fields of spec on base, the v2 spec kept in SpecAnnotation.
+// The owners of base are kept while the first of them matches NamespaceOwner.
+func specToV2(spec *NamespaceconfigSpec, base v2.NamespaceconfigSpec) v2.NamespaceconfigSpec {
+	base.Environment = spec.Environment
+	base.Abbreviation = spec.Abbreviation
+	base.Size = spec.NamespaceSize
+	base.Limits = spec.NamespaceLimits.DeepCopy()
+	base.Quota = spec.NamespaceQuota.DeepCopy()
+	base.DeletionPolicy = v2.DeletionPolicy(spec.DeletionPolicy)
+	base.DeletionProtection = spec.DeletionProtection
+	switch {
+	case spec.NamespaceOwner == "":
+		base.Owners = nil
+	case len(base.Owners) == 0 || base.Owners[0].Name != spec.NamespaceOwner:
+		base.Owners = []v2.Owner{{Kind: v2.OwnerUser, Name: spec.NamespaceOwner, Role: v2.OwnerAdmin}}
+	}
+	return base
+}
+
+// summary is the v1 Status of the given conditions: TERMINATING while deleting,
+// RUNNING once ready and FAILED otherwise
+func summary(conditions []metav1.Condition) string {
+	switch {
+	case len(conditions) == 0:
+		return ""
+	case meta.IsStatusConditionTrue(conditions, ConditionDeleting):
+		return "TERMINATING"
+	case meta.IsStatusConditionTrue(conditions, ConditionReady):
+		return "RUNNING"
+	}
+	return "FAILED"
+}
+
+// lastTransition is the latest transition time of the given conditions
+func lastTransition(conditions []metav1.Condition) *metav1.Time {
+	var last *metav1.Time
+	for i := range conditions {
+		if last == nil || last.Before(&conditions[i].LastTransitionTime) {
+			last = &conditions[i].LastTransitionTime
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	return last.DeepCopy()
+}
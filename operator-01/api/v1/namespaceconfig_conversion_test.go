@@ -0,0 +1,97 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("Namespaceconfig conversion", func() {
+	var hub *v2.Namespaceconfig
+
+	BeforeEach(func() {
+		hub = &v2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Generation: 2},
+			Spec: v2.NamespaceconfigSpec{
+				Environment:    "prod",
+				Abbreviation:   "pay",
+				Owners:         []v2.Owner{{Kind: v2.OwnerUser, Name: "mukund", Role: v2.OwnerAdmin}},
+				Size:           "m",
+				Quota:          &corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("400")}},
+				DeletionPolicy: v2.DeletionPolicyRetain,
+			},
+			Status: v2.NamespaceconfigStatus{
+				NamespaceName:      "pay-prod",
+				AppliedSize:        "m",
+				ObservedGeneration: 2,
+				Conditions: []metav1.Condition{{
+					Type:   v2.ConditionReady,
+					Status: metav1.ConditionTrue,
+					Reason: "Provisioned",
+				}},
+				Steps: []v2.ProvisioningStep{{Name: v2.StepSize, State: v2.StepSucceeded}},
+			},
+		}
+	})
+
+	It("maps the fields of v2 to v1 without extra annotation when nothing is lost", func() {
+		nc := &Namespaceconfig{}
+		Expect(nc.ConvertFrom(hub)).To(Succeed())
+		Expect(nc.Spec.NamespaceOwner).To(Equal("mukund"))
+		Expect(nc.Spec.NamespaceSize).To(Equal("m"))
+		Expect(nc.Spec.DeletionPolicy).To(Equal(DeletionPolicyRetain))
+		Expect(nc.Status.NamespaceName).To(Equal("pay-prod"))
+		Expect(nc.Status.Status).To(Equal("RUNNING"))
+		Expect(nc.Status.Steps).To(HaveLen(1))
+		Expect(nc.GetAnnotations()).NotTo(HaveKey(SpecAnnotation))
+
+		roundTrip := &v2.Namespaceconfig{}
+		Expect(nc.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip.Spec).To(Equal(hub.Spec))
+		Expect(roundTrip.Status).To(Equal(hub.Status))
+	})
+
+	It("keeps what v1 cannot represent through a round trip", func() {
+		hub.Spec.Owners = []v2.Owner{
+			{Kind: v2.OwnerServiceAccount, Namespace: "ci", Name: "deployer", Role: v2.OwnerAdmin, Email: "ci@example.com"},
+			{Kind: v2.OwnerGroup, Name: "auditors", Role: v2.OwnerView},
+		}
+		nc := &Namespaceconfig{}
+		Expect(nc.ConvertFrom(hub)).To(Succeed())
+		Expect(nc.Spec.NamespaceOwner).To(Equal("deployer"))
+		Expect(nc.GetAnnotations()).To(HaveKey(SpecAnnotation))
+
+		roundTrip := &v2.Namespaceconfig{}
+		Expect(nc.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip.Spec).To(Equal(hub.Spec))
+		Expect(roundTrip.GetAnnotations()).NotTo(HaveKey(SpecAnnotation))
+	})
+
+	It("applies changes made through v1 on top of the kept v2 fields", func() {
+		hub.Spec.Owners = []v2.Owner{{Kind: v2.OwnerGroup, Name: "payments-team", Role: v2.OwnerAdmin}}
+		nc := &Namespaceconfig{}
+		Expect(nc.ConvertFrom(hub)).To(Succeed())
+		nc.Spec.NamespaceSize = "l"
+		roundTrip := &v2.Namespaceconfig{}
+		Expect(nc.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip.Spec.Size).To(Equal("l"))
+		Expect(roundTrip.Spec.Owners[0].Kind).To(Equal(v2.OwnerGroup))
+
+		nc.Spec.NamespaceOwner = "mukund"
+		Expect(nc.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip.Spec.Owners).To(Equal([]v2.Owner{{Kind: v2.OwnerUser, Name: "mukund", Role: v2.OwnerAdmin}}))
+	})
+
+	It("summarizes the conditions in the v1 status", func() {
+		meta := []metav1.Condition{{Type: v2.ConditionReady, Status: metav1.ConditionFalse}}
+		Expect(summary(meta)).To(Equal("FAILED"))
+		meta = append(meta, metav1.Condition{Type: v2.ConditionDeleting, Status: metav1.ConditionTrue})
+		Expect(summary(meta)).To(Equal("TERMINATING"))
+		Expect(summary(nil)).To(BeEmpty())
+	})
+})
@@ -17,7 +17,7 @@ limitations under the License.
 package v1
 
 import (
-	//v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,29 +29,148 @@ type NamespaceconfigSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Environment and Abbreviation make up the namespace name Abbreviation-Environment.
+	// Both are immutable, renaming a namespace takes a new Namespaceconfig.
 	//+kubebuilder:validation:MaxLength=5
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="Environment is immutable"
 	Environment string `json:"Environment"`
 	//+kubebuilder:validation:MaxLength=8
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="Abbreviation is immutable"
 	Abbreviation string `json:"Abbreviation"`
-	// NamespaceLimits v1.LimitRangeSpec    `json:"NamespaceLimits,omitempty"`
-	// NamespaceQuota  v1.ResourceQuotaSpec `json:"NamespaceQuota,omitempty"`
-	NamespaceOwner string `json:"NamespaceOwner,omitempty"`
-	//+kubebuilder:validation:Enum=S;M;L
+	// NamespaceLimits is merged on top of the LimitRange of the size profile.
+	// Items are matched by type and only the resources set here are overridden.
+	NamespaceLimits *corev1.LimitRangeSpec `json:"NamespaceLimits,omitempty"`
+	// NamespaceQuota is merged on top of the ResourceQuota of the size profile.
+	// Only the hard limits set here are overridden; scopes replace the profile scopes.
+	NamespaceQuota *corev1.ResourceQuotaSpec `json:"NamespaceQuota,omitempty"`
+	NamespaceOwner string                    `json:"NamespaceOwner,omitempty"`
+	// NamespaceSize is the name of the NamespaceSizeProfile providing the
+	// LimitRange and ResourceQuota of the namespace. It is matched case-insensitively.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`
 	NamespaceSize string `json:"NamespaceSize,omitempty"`
+	// DeletionPolicy tells what happens to the namespace when the Namespaceconfig
+	// is deleted. When empty the cluster default of the Environment applies.
+	//+optional
+	DeletionPolicy DeletionPolicy `json:"DeletionPolicy,omitempty"`
+	// DeletionProtection rejects the deletion of the Namespaceconfig and of its
+	// namespace while true. When unset the cluster default of the Environment
+	// applies. Turning it off takes an update that changes nothing else.
+	//+optional
+	DeletionProtection *bool `json:"DeletionProtection,omitempty"`
 }
 
+// DeletionPolicy tells what happens to the namespace of a deleted Namespaceconfig
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the namespace with everything in it
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the namespace and its content but removes the
+	// metadata of the operator, the namespace is no longer managed
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the namespace and its content as they are
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
 // NamespaceconfigStatus defines the observed state of Namespaceconfig
 type NamespaceconfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	NamespaceName string `json:"NamespaceName,omitempty"`
-	Status        string `json:"Status,omitempty"`
-	LastUpdate    string `json:"LastUpdate,omitempty"`
+	// Status is a one word summary of the conditions: RUNNING, FAILED or TERMINATING
+	// while the namespace of a deleted Namespaceconfig is going away
+	Status string `json:"Status,omitempty"`
+	// LastUpdateTime is the last time the status changed
+	LastUpdateTime *metav1.Time `json:"LastUpdateTime,omitempty"`
+	// EffectiveLimits is the LimitRange applied to the namespace: the size
+	// profile merged with NamespaceLimits
+	EffectiveLimits *corev1.LimitRangeSpec `json:"EffectiveLimits,omitempty"`
+	// EffectiveQuota is the ResourceQuota applied to the namespace: the size
+	// profile merged with NamespaceQuota
+	EffectiveQuota *corev1.ResourceQuotaSpec `json:"EffectiveQuota,omitempty"`
+	// AppliedSize is the NamespaceSizeProfile currently applied to the namespace.
+	// It differs from NamespaceSize while a resize is refused.
+	AppliedSize string `json:"AppliedSize,omitempty"`
+	// ObservedGeneration is the generation of the spec the status was computed for.
+	// It and conditions use the conventional lower case keys read by kubectl wait.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the latest observations of the Namespaceconfig
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Steps report the progress of each step of provisioning the namespace
+	//+listType=map
+	//+listMapKey=Name
+	Steps []ProvisioningStep `json:"Steps,omitempty"`
 }
 
+// ProvisioningStep reports the outcome of one step of provisioning a namespace
+type ProvisioningStep struct {
+	// Name of the step: Size, Namespace, LimitRange, ResourceQuota or Cleanup
+	Name string `json:"Name"`
+	// State of the step
+	//+kubebuilder:validation:Enum=Pending;Succeeded;Failed
+	State StepState `json:"State"`
+	// Message tells why the step failed or is pending
+	Message string `json:"Message,omitempty"`
+	// LastTransitionTime is the last time the state changed
+	LastTransitionTime metav1.Time `json:"LastTransitionTime"`
+}
+
+// StepState is the state of a provisioning step
+type StepState string
+
+// States of a provisioning step
+const (
+	StepPending   StepState = "Pending"
+	StepSucceeded StepState = "Succeeded"
+	StepFailed    StepState = "Failed"
+)
+
+// Provisioning steps of a Namespaceconfig, in the order they run
+const (
+	StepSize          = "Size"
+	StepNamespace     = "Namespace"
+	StepLimitRange    = "LimitRange"
+	StepResourceQuota = "ResourceQuota"
+	StepCleanup       = "Cleanup"
+)
+
+// Annotations set by the operator on the namespace of a Namespaceconfig
+const (
+	// NamespaceconfigAnnotation names the Namespaceconfig that manages a namespace
+	NamespaceconfigAnnotation = "namespaceconfig.myoperator.io/name"
+	// ClaimAnnotation holds the UID of the Namespaceconfig owning a namespace
+	ClaimAnnotation = "namespaceconfig.myoperator.io/uid"
+)
+
+// Condition types of a Namespaceconfig
+const (
+	// ConditionReady is True once the namespace and all its children are applied
+	ConditionReady = "Ready"
+	// ConditionNamespaceProvisioned reports whether the namespace is applied
+	ConditionNamespaceProvisioned = "NamespaceProvisioned"
+	// ConditionLimitsApplied reports whether the LimitRange is applied
+	ConditionLimitsApplied = "LimitsApplied"
+	// ConditionQuotaApplied reports whether the ResourceQuota is applied
+	ConditionQuotaApplied = "QuotaApplied"
+	// ConditionDeleting is True while the Namespaceconfig is being deleted. Its
+	// reason is NamespaceStuck when the namespace does not finish terminating.
+	ConditionDeleting = "Deleting"
+	// ConditionResized reports whether the last change of NamespaceSize was applied
+	ConditionResized = "Resized"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster,shortName={"nsc","nc","nsconfig"}
+//+kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.NamespaceName`
+//+kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.AppliedSize`
+//+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.Status`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Namespaceconfig is the Schema for the namespaceconfigs API
 type Namespaceconfig struct {
@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSizeProfileSpec defines the LimitRange and ResourceQuota applied to
+// every namespace whose Namespaceconfig references this profile
+type NamespaceSizeProfileSpec struct {
+	// LimitRange is copied into the LimitRange created in the namespace
+	LimitRange corev1.LimitRangeSpec `json:"LimitRange,omitempty"`
+	// ResourceQuota is copied into the ResourceQuota created in the namespace
+	ResourceQuota corev1.ResourceQuotaSpec `json:"ResourceQuota,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName={"nsp","nssp","sizeprofile"}
+
+// NamespaceSizeProfile is the Schema for the namespacesizeprofiles API.
+// A Namespaceconfig selects a profile by setting spec.size (NamespaceSize in v1) to the profile name.
+type NamespaceSizeProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NamespaceSizeProfileSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespaceSizeProfileList contains a list of NamespaceSizeProfile
+type NamespaceSizeProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceSizeProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceSizeProfile{}, &NamespaceSizeProfileList{})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceClaimSpec defines the namespace requested by a NamespaceClaim
+// +kubebuilder:validation:XValidation:rule="has(self.namespaceconfigName) || (has(self.environment) && has(self.abbreviation))",message="environment and abbreviation are required without namespaceconfigName"
+type NamespaceClaimSpec struct {
+	// namespaceconfigName binds the claim to an existing Namespaceconfig reserved
+	// for it through its claimRef. When empty a Namespaceconfig is provisioned
+	// for the claim.
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="namespaceconfigName is immutable"
+	//+optional
+	NamespaceconfigName string `json:"namespaceconfigName,omitempty"`
+	// environment and abbreviation make up the name abbreviation-environment of
+	// a provisioned namespace
+	//+kubebuilder:validation:MaxLength=5
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="environment is immutable"
+	//+optional
+	Environment string `json:"environment,omitempty"`
+	//+kubebuilder:validation:MaxLength=8
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="abbreviation is immutable"
+	//+optional
+	Abbreviation string `json:"abbreviation,omitempty"`
+	// owners of a provisioned namespace, the user creating the claim by default
+	//+optional
+	Owners []Owner `json:"owners,omitempty"`
+	// size of a provisioned namespace, the default of the environment when empty
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`
+	//+optional
+	Size string `json:"size,omitempty"`
+}
+
+// ClaimPhase is the binding phase of a NamespaceClaim
+type ClaimPhase string
+
+// Phases of a NamespaceClaim
+const (
+	// ClaimPending is a claim not bound yet
+	ClaimPending ClaimPhase = "Pending"
+	// ClaimBound is a claim bound to a Namespaceconfig
+	ClaimBound ClaimPhase = "Bound"
+	// ClaimLost is a claim whose Namespaceconfig is gone
+	ClaimLost ClaimPhase = "Lost"
+)
+
+// NamespaceClaimStatus defines the observed state of NamespaceClaim
+type NamespaceClaimStatus struct {
+	// phase of the binding
+	//+optional
+	Phase ClaimPhase `json:"phase,omitempty"`
+	// namespaceconfigName is the Namespaceconfig the claim is bound to
+	//+optional
+	NamespaceconfigName string `json:"namespaceconfigName,omitempty"`
+	// namespaceName is the namespace of the bound Namespaceconfig
+	//+optional
+	NamespaceName string `json:"namespaceName,omitempty"`
+	// observedGeneration is the generation of the spec the status was computed for
+	//+optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// conditions are Bound, and Ready copied from the bound Namespaceconfig
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ConditionBound reports whether a NamespaceClaim is bound to its Namespaceconfig
+const ConditionBound = "Bound"
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName={"nsclaim"}
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Namespaceconfig",type=string,JSONPath=`.status.namespaceconfigName`
+//+kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.namespaceName`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NamespaceClaim is a request of a tenant for a namespace. It binds to a
+// cluster-scoped Namespaceconfig like a PersistentVolumeClaim binds to a
+// PersistentVolume.
+type NamespaceClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespaceClaimSpec   `json:"spec,omitempty"`
+	Status NamespaceClaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespaceClaimList contains a list of NamespaceClaim
+type NamespaceClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceClaim{}, &NamespaceClaimList{})
+}
@@ -0,0 +1,382 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NamespaceconfigSpec defines the desired state of Namespaceconfig
+type NamespaceconfigSpec struct {
+	// environment and abbreviation make up the namespace name abbreviation-environment.
+	// Both are immutable, renaming a namespace takes a new Namespaceconfig.
+	//+kubebuilder:validation:MaxLength=5
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="environment is immutable"
+	Environment string `json:"environment"`
+	//+kubebuilder:validation:MaxLength=8
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="abbreviation is immutable"
+	Abbreviation string `json:"abbreviation"`
+	// owners are the users, groups and service accounts the namespace belongs
+	// to, each bound to its role in the namespace
+	//+optional
+	Owners []Owner `json:"owners,omitempty"`
+	// access grants roles in the namespace to groups or service accounts
+	// besides the owners
+	//+optional
+	Access []AccessGrant `json:"access,omitempty"`
+	// size is the name of the NamespaceSizeProfile providing the LimitRange and
+	// ResourceQuota of the namespace. It is matched case-insensitively.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`
+	//+optional
+	Size string `json:"size,omitempty"`
+	// limits is merged on top of the LimitRange of the size profile.
+	// Items are matched by type and only the resources set here are overridden.
+	//+optional
+	Limits *corev1.LimitRangeSpec `json:"limits,omitempty"`
+	// quota is merged on top of the ResourceQuota of the size profile.
+	// Only the hard limits set here are overridden; scopes replace the profile scopes.
+	//+optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+	// deletionPolicy tells what happens to the namespace when the Namespaceconfig
+	// is deleted. When empty the cluster default of the environment applies.
+	//+optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// deletionProtection rejects the deletion of the Namespaceconfig and of its
+	// namespace while true. When unset the cluster default of the environment
+	// applies. Turning it off takes an update that changes nothing else.
+	//+optional
+	DeletionProtection *bool `json:"deletionProtection,omitempty"`
+	// disableNetworkPolicies opts the namespace out of the NetworkPolicies of its
+	// environment. The NetworkPolicies already applied are deleted.
+	//+optional
+	DisableNetworkPolicies bool `json:"disableNetworkPolicies,omitempty"`
+	// podSecurity overrides the Pod Security Standard of the environment enforced
+	// in the namespace. It takes the annotation
+	// namespaceconfig.myoperator.io/pod-security-exemption with the reason of
+	// the exemption.
+	//+kubebuilder:validation:Enum=privileged;baseline;restricted
+	//+optional
+	PodSecurity PodSecurityLevel `json:"podSecurity,omitempty"`
+	// namespaceLabels are added to the labels of the namespace. The labels set
+	// by the operator, kubernetes.io/metadata.name and the keys starting with
+	// namespaceconfig.myoperator.io/ or pod-security.kubernetes.io/ are reserved.
+	// Labels removed from here are removed from the namespace.
+	//+optional
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	// namespaceAnnotations are added to the annotations of the namespace, with
+	// the same reserved keys as namespaceLabels
+	//+optional
+	NamespaceAnnotations map[string]string `json:"namespaceAnnotations,omitempty"`
+	// templateSelector selects NamespaceTemplates by their labels, in addition
+	// to the templates of the environment
+	//+optional
+	TemplateSelector *metav1.LabelSelector `json:"templateSelector,omitempty"`
+	// claimRef is the NamespaceClaim bound to the Namespaceconfig. Setting it
+	// on a Namespaceconfig reserves it for that claim.
+	//+optional
+	ClaimRef *ClaimReference `json:"claimRef,omitempty"`
+}
+
+// ClaimReference references a NamespaceClaim
+type ClaimReference struct {
+	// namespace of the NamespaceClaim
+	Namespace string `json:"namespace"`
+	// name of the NamespaceClaim
+	Name string `json:"name"`
+	// uid of the NamespaceClaim, set once it is bound
+	//+optional
+	UID types.UID `json:"uid,omitempty"`
+}
+
+// Owner is a user, group or service account owning a namespace
+type Owner struct {
+	// kind of the owner
+	//+kubebuilder:validation:Enum=User;Group;ServiceAccount
+	//+kubebuilder:default=User
+	//+optional
+	Kind OwnerKind `json:"kind,omitempty"`
+	// name of the user, group or service account, without whitespace as it
+	// ends up in the manifests of NamespaceTemplates
+	//+kubebuilder:validation:MinLength=1
+	//+kubebuilder:validation:Pattern=`^\S+$`
+	Name string `json:"name"`
+	// namespace of a service account, the namespace of the Namespaceconfig when empty
+	//+optional
+	Namespace string `json:"namespace,omitempty"`
+	// role of the owner in the namespace
+	//+kubebuilder:validation:Enum=admin;edit;view
+	//+kubebuilder:default=admin
+	//+optional
+	Role OwnerRole `json:"role,omitempty"`
+	// email to contact the owner
+	//+kubebuilder:validation:Pattern=`^\S+$`
+	//+optional
+	Email string `json:"email,omitempty"`
+}
+
+// OwnerRole is the ClusterRole an Owner is bound to in the namespace
+type OwnerRole string
+
+// Roles of an owner
+const (
+	OwnerAdmin OwnerRole = "admin"
+	OwnerEdit  OwnerRole = "edit"
+	OwnerView  OwnerRole = "view"
+)
+
+// AccessGrant binds a ClusterRole in the namespace to a group or service account
+type AccessGrant struct {
+	// kind of the subject
+	//+kubebuilder:validation:Enum=Group;ServiceAccount
+	Kind OwnerKind `json:"kind"`
+	// name of the group or service account
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// namespace of a service account, the namespace of the Namespaceconfig when empty
+	//+optional
+	Namespace string `json:"namespace,omitempty"`
+	// role is the ClusterRole bound in the namespace
+	//+kubebuilder:default=view
+	//+optional
+	Role string `json:"role,omitempty"`
+}
+
+// OwnerKind is the kind of an Owner
+type OwnerKind string
+
+// Kinds of owners
+const (
+	OwnerUser           OwnerKind = "User"
+	OwnerGroup          OwnerKind = "Group"
+	OwnerServiceAccount OwnerKind = "ServiceAccount"
+)
+
+// PodSecurityLevel is a level of the Pod Security Standards
+type PodSecurityLevel string
+
+// Levels of the Pod Security Standards
+const (
+	PodSecurityPrivileged PodSecurityLevel = "privileged"
+	PodSecurityBaseline   PodSecurityLevel = "baseline"
+	PodSecurityRestricted PodSecurityLevel = "restricted"
+)
+
+// PodSecurityExemptionAnnotation gives the reason of the podSecurity override
+// of a Namespaceconfig
+const PodSecurityExemptionAnnotation = "namespaceconfig.myoperator.io/pod-security-exemption"
+
+// DeletionPolicy tells what happens to the namespace of a deleted Namespaceconfig
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the namespace with everything in it
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the namespace and its content but removes the
+	// metadata of the operator, the namespace is no longer managed
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the namespace and its content as they are
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// NamespaceconfigStatus defines the observed state of Namespaceconfig
+type NamespaceconfigStatus struct {
+	// namespaceName is the namespace managed by the Namespaceconfig
+	//+optional
+	NamespaceName string `json:"namespaceName,omitempty"`
+	// appliedSize is the NamespaceSizeProfile currently applied to the namespace.
+	// It differs from size while a resize is refused.
+	//+optional
+	AppliedSize string `json:"appliedSize,omitempty"`
+	// effectiveLimits is the LimitRange applied to the namespace: the size
+	// profile merged with limits
+	//+optional
+	EffectiveLimits *corev1.LimitRangeSpec `json:"effectiveLimits,omitempty"`
+	// effectiveQuota is the ResourceQuota applied to the namespace: the size
+	// profile merged with quota
+	//+optional
+	EffectiveQuota *corev1.ResourceQuotaSpec `json:"effectiveQuota,omitempty"`
+	// observedGeneration is the generation of the spec the status was computed for
+	//+optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// conditions describe the latest observations of the Namespaceconfig
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// templateObjects are the objects applied into the namespace from
+	// NamespaceTemplates. Objects no longer rendered are deleted.
+	//+optional
+	TemplateObjects []TemplateObject `json:"templateObjects,omitempty"`
+	// steps report the progress of each step of provisioning the namespace
+	//+listType=map
+	//+listMapKey=name
+	//+optional
+	Steps []ProvisioningStep `json:"steps,omitempty"`
+}
+
+// TemplateObject references an object applied from a NamespaceTemplate
+type TemplateObject struct {
+	// template is the name of the NamespaceTemplate
+	Template string `json:"template"`
+	// apiVersion of the object
+	APIVersion string `json:"apiVersion"`
+	// kind of the object
+	Kind string `json:"kind"`
+	// name of the object in the namespace
+	Name string `json:"name"`
+}
+
+// ProvisioningStep reports the outcome of one step of provisioning a namespace
+type ProvisioningStep struct {
+	// name of the step: Size, Namespace, LimitRange, ResourceQuota, RoleBindings,
+	// NetworkPolicies, Templates, Replication or Cleanup
+	Name string `json:"name"`
+	// state of the step
+	//+kubebuilder:validation:Enum=Pending;Succeeded;Failed
+	State StepState `json:"state"`
+	// message tells why the step failed or is pending
+	//+optional
+	Message string `json:"message,omitempty"`
+	// lastTransitionTime is the last time the state changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// StepState is the state of a provisioning step
+type StepState string
+
+// States of a provisioning step
+const (
+	StepPending   StepState = "Pending"
+	StepSucceeded StepState = "Succeeded"
+	StepFailed    StepState = "Failed"
+)
+
+// Provisioning steps of a Namespaceconfig, in the order they run
+const (
+	StepSize            = "Size"
+	StepNamespace       = "Namespace"
+	StepLimitRange      = "LimitRange"
+	StepResourceQuota   = "ResourceQuota"
+	StepRoleBindings    = "RoleBindings"
+	StepNetworkPolicies = "NetworkPolicies"
+	StepTemplates       = "Templates"
+	StepReplication     = "Replication"
+	StepCleanup         = "Cleanup"
+)
+
+// Annotations set by the operator on the namespace of a Namespaceconfig
+const (
+	// OwnersAnnotation lists the owners of a namespace as JSON, with the names
+	// and emails that do not fit in a label
+	OwnersAnnotation = "namespaceconfig.myoperator.io/owners"
+	// NamespaceconfigAnnotation names the Namespaceconfig that manages a namespace
+	NamespaceconfigAnnotation = "namespaceconfig.myoperator.io/name"
+	// ClaimAnnotation holds the UID of the Namespaceconfig owning a namespace
+	ClaimAnnotation = "namespaceconfig.myoperator.io/uid"
+	// ProvisionedByAnnotation marks a Namespaceconfig created for a NamespaceClaim,
+	// it is deleted with the claim
+	ProvisionedByAnnotation = "namespaceconfig.myoperator.io/provisioned-by"
+)
+
+// Annotations and labels of the Secrets and ConfigMaps replicated into namespaces
+const (
+	// ReplicateAnnotation set to "true" on a Secret or ConfigMap of the
+	// replication namespace copies it into the managed namespaces
+	ReplicateAnnotation = "namespaceconfig.myoperator.io/replicate"
+	// ReplicateEnvironmentsAnnotation restricts the replication to the
+	// namespaces of a comma separated list of environments
+	ReplicateEnvironmentsAnnotation = "namespaceconfig.myoperator.io/replicate-environments"
+	// ReplicateSizesAnnotation restricts the replication to the namespaces of
+	// a comma separated list of sizes
+	ReplicateSizesAnnotation = "namespaceconfig.myoperator.io/replicate-sizes"
+	// ReplicatedFromAnnotation is set on copies to the namespace/name of their source
+	ReplicatedFromAnnotation = "namespaceconfig.myoperator.io/replicated-from"
+	// ReplicatedLabel set to "true" marks copies, so that they are listed without
+	// the other Secrets and ConfigMaps of a namespace
+	ReplicatedLabel = "namespaceconfig.myoperator.io/replicated"
+)
+
+// Condition types of a Namespaceconfig
+const (
+	// ConditionReady is True once the namespace and all its children are applied
+	ConditionReady = "Ready"
+	// ConditionNamespaceProvisioned reports whether the namespace is applied
+	ConditionNamespaceProvisioned = "NamespaceProvisioned"
+	// ConditionLimitsApplied reports whether the LimitRange is applied
+	ConditionLimitsApplied = "LimitsApplied"
+	// ConditionQuotaApplied reports whether the ResourceQuota is applied
+	ConditionQuotaApplied = "QuotaApplied"
+	// ConditionDeleting is True while the Namespaceconfig is being deleted. Its
+	// reason is NamespaceStuck when the namespace does not finish terminating.
+	ConditionDeleting = "Deleting"
+	// ConditionResized reports whether the last change of size was applied
+	ConditionResized = "Resized"
+	// ConditionAccessGranted reports whether the RoleBindings of the owners and
+	// of access are applied
+	ConditionAccessGranted = "AccessGranted"
+	// ConditionNetworkIsolated reports whether the NetworkPolicies of the
+	// environment are applied. It is False with reason Disabled when the
+	// Namespaceconfig opts out of them.
+	ConditionNetworkIsolated = "NetworkIsolated"
+	// ConditionTemplatesApplied reports whether the objects of the selected
+	// NamespaceTemplates are applied
+	ConditionTemplatesApplied = "TemplatesApplied"
+	// ConditionReplicated reports whether the Secrets and ConfigMaps of the
+	// replication namespace are copied. It is False with reason Conflict when
+	// an object of the namespace has the name of a source.
+	ConditionReplicated = "Replicated"
+	// ConditionPodSecurityOverridden is set while spec.podSecurity overrides the
+	// level of the environment. It is False with reason ExemptionMissing when the
+	// override is ignored for lack of the exemption annotation.
+	ConditionPodSecurityOverridden = "PodSecurityOverridden"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:resource:scope=Cluster,shortName={"nsc","nc","nsconfig"}
+//+kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.namespaceName`
+//+kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.appliedSize`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Namespaceconfig is the Schema for the namespaceconfigs API
+type Namespaceconfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespaceconfigSpec   `json:"spec,omitempty"`
+	Status NamespaceconfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespaceconfigList contains a list of Namespaceconfig
+type NamespaceconfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Namespaceconfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Namespaceconfig{}, &NamespaceconfigList{})
+}
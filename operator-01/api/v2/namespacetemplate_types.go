@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceTemplateSpec defines the manifests a NamespaceTemplate seeds into namespaces
+type NamespaceTemplateSpec struct {
+	// environments are the environments whose namespaces get the manifests.
+	// Namespaceconfigs of other environments select the template through
+	// their templateSelector.
+	//+optional
+	Environments []string `json:"environments,omitempty"`
+	// manifests are Kubernetes objects as YAML documents separated by ---,
+	// rendered as a Go template with the fields .Name, .Namespace, .Environment,
+	// .Abbreviation, .Owner, .Owners and .Size of the Namespaceconfig. The
+	// objects must be namespaced, they are applied into the namespace.
+	//+kubebuilder:validation:MinLength=1
+	Manifests string `json:"manifests"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName={"nstemplate"}
+//+kubebuilder:printcolumn:name="Environments",type=string,JSONPath=`.spec.environments`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NamespaceTemplate holds manifests applied into the namespaces of the
+// Namespaceconfigs that select it
+type NamespaceTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NamespaceTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespaceTemplateList contains a list of NamespaceTemplate
+type NamespaceTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceTemplate{}, &NamespaceTemplateList{})
+}
@@ -24,16 +24,23 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
 	"github.com/dguyhasnoname/ohmyk8s-operator/internal/controller"
+	webhookv1 "github.com/dguyhasnoname/ohmyk8s-operator/internal/webhook/v1"
+	webhookv2 "github.com/dguyhasnoname/ohmyk8s-operator/internal/webhook/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -46,6 +53,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(namespaceconfigv1.AddToScheme(scheme))
+	utilruntime.Must(namespaceconfigv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -53,21 +61,79 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	deletionPolicies := config.DeletionPolicies{}
+	var deletionDenyList config.GroupResources
+	protectedEnvironments := config.Environments{}
+	allowedEnvironments := config.Environments{}
+	reservedPrefixes := config.List{"kube-", "openshift-"}
+	accessRoles := config.List{"admin", "edit", "view"}
+	defaultSizes := config.PerEnvironment{}
+	networkPolicies := config.NetworkPolicyBundles{}
+	var ingressNamespace string
+	podSecurity := config.PodSecurityLevels{}
+	var podSecurityVersion string
+	var replicationNamespace string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.Var(deletionPolicies, "deletion-policy",
+		"Default DeletionPolicy of Namespaceconfigs per environment, as environment=policy pairs. "+
+			"The environment * matches any environment. Environments without a policy use Delete.")
+	flag.Var(&deletionDenyList, "deletion-deny-list",
+		"Resources, as resource.group, that block the deletion of a namespace containing any of them. "+
+			"The manager needs the permission to list them.")
+	flag.Var(protectedEnvironments, "deletion-protection",
+		"Environments where DeletionProtection of Namespaceconfigs defaults to true, as a comma separated list.")
+	flag.Var(allowedEnvironments, "allowed-environments",
+		"Environments accepted by the webhook, as a comma separated list. Any environment is accepted when empty.")
+	flag.Var(&reservedPrefixes, "reserved-prefixes",
+		"Prefixes namespace names of Namespaceconfigs must not start with, as a comma separated list.")
+	flag.Var(&accessRoles, "access-roles",
+		"ClusterRoles the access of Namespaceconfigs may grant in their namespace, as a comma separated list.")
+	flag.Var(defaultSizes, "default-size",
+		"Default size of Namespaceconfigs per environment, as environment=size pairs. "+
+			"The environment * matches any environment. Defaults to *=s.")
+	flag.Var(networkPolicies, "network-policies",
+		"NetworkPolicy bundle of namespaces per environment, as environment=bundle pairs with the bundles "+
+			"none, baseline or strict. The environment * matches any environment. Defaults to *=baseline.")
+	flag.StringVar(&ingressNamespace, "ingress-namespace", "ingress-nginx",
+		"Namespace of the ingress controller allowed into every namespace by the NetworkPolicies.")
+	flag.Var(podSecurity, "pod-security",
+		"Pod Security Standard enforced in namespaces per environment, as environment=level pairs with the levels "+
+			"privileged, baseline or restricted. The environment * matches any environment. Defaults to *=baseline.")
+	flag.StringVar(&podSecurityVersion, "pod-security-version", "latest",
+		"Version of the Pod Security Standards the pod-security.kubernetes.io labels of namespaces are pinned to.")
+	flag.StringVar(&replicationNamespace, "replication-namespace", "",
+		"Namespace whose Secrets and ConfigMaps annotated with "+namespaceconfigv2.ReplicateAnnotation+"=true "+
+			"are replicated into the managed namespaces. Nothing is replicated when empty.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
+	if len(defaultSizes) == 0 {
+		defaultSizes["*"] = "s"
+	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Secrets and ConfigMaps are read from the API server, only the ones of the
+	// replication namespace are cached and watched
+	cacheOptions := cache.Options{}
+	if replicationNamespace != "" {
+		sources := cache.ByObject{Namespaces: map[string]cache.Config{replicationNamespace: {}}}
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{&corev1.Secret{}: sources, &corev1.ConfigMap{}: sources}
+	}
+	clientOptions := client.Options{Cache: &client.CacheOptions{
+		DisableFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}},
+	}}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
+		Client:                 clientOptions,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -90,12 +156,72 @@ func main() {
 	}
 
 	if err = (&controller.NamespaceconfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("namespaceconfig-controller"),
+
+		APIReader:        mgr.GetAPIReader(),
+		DeletionPolicies: deletionPolicies,
+		DeletionDenyList: deletionDenyList,
+
+		ProtectedEnvironments: protectedEnvironments,
+		NetworkPolicies:       networkPolicies,
+		IngressNamespace:      ingressNamespace,
+		PodSecurity:           podSecurity,
+		PodSecurityVersion:    podSecurityVersion,
+		ReplicationNamespace:  replicationNamespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Namespaceconfig")
 		os.Exit(1)
 	}
+	if err = (&controller.NamespaceClaimReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("namespaceclaim-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NamespaceClaim")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&webhookv2.NamespaceconfigDefaulter{
+			Sizes: defaultSizes,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Namespaceconfig")
+			os.Exit(1)
+		}
+		if err = (&webhookv2.NamespaceconfigValidator{
+			Client:                mgr.GetClient(),
+			Recorder:              mgr.GetEventRecorderFor("namespaceconfig-webhook"),
+			ProtectedEnvironments: protectedEnvironments,
+			AllowedEnvironments:   allowedEnvironments,
+			ReservedPrefixes:      reservedPrefixes,
+			AccessRoles:           accessRoles,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Namespaceconfig")
+			os.Exit(1)
+		}
+		if err = (&webhookv1.NamespaceSizeProfileValidator{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceSizeProfile")
+			os.Exit(1)
+		}
+		if err = (&webhookv2.NamespaceClaimDefaulter{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceClaim")
+			os.Exit(1)
+		}
+		if err = (&webhookv2.NamespaceClaimValidator{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceClaim")
+			os.Exit(1)
+		}
+		if err = (&webhookv2.NamespaceValidator{
+			Client:                mgr.GetClient(),
+			ProtectedEnvironments: protectedEnvironments,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Namespace")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+const (
+	// roleBindingPrefix starts the names of the RoleBindings of a namespace
+	roleBindingPrefix = "namespaceconfig-"
+	// ownersAnnotation lists the owners of a namespace as JSON
+	ownersAnnotation = namespaceconfigv2.OwnersAnnotation
+)
+
+// subject is the RBAC subject of a kind, name and namespace of a Namespaceconfig.
+// Service accounts without namespace are looked up in namespaceName.
+func subject(kind namespaceconfigv2.OwnerKind, name, namespace, namespaceName string) rbacv1.Subject {
+	switch kind {
+	case namespaceconfigv2.OwnerServiceAccount:
+		if namespace == "" {
+			namespace = namespaceName
+		}
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}
+	case namespaceconfigv2.OwnerGroup:
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+	}
+	return rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: name}
+}
+
+// nsRoleBindings builds the RoleBindings of the namespace: one
+// namespaceconfig-owner-<role> per role of the owners, and one
+// namespaceconfig-role-<role> per role of access binds their subjects. The
+// roleRef of a RoleBinding cannot change, so a subject moving to another role
+// moves to another RoleBinding.
+func (r *NamespaceconfigReconciler) nsRoleBindings(nc *namespaceconfigv2.Namespaceconfig, namespaceName string) ([]*rbacv1.RoleBinding, error) {
+	owners := map[string][]rbacv1.Subject{}
+	for _, owner := range nc.Spec.Owners {
+		role := string(owner.Role)
+		if role == "" {
+			role = string(namespaceconfigv2.OwnerAdmin)
+		}
+		owners[role] = append(owners[role], subject(owner.Kind, owner.Name, owner.Namespace, namespaceName))
+	}
+	access := map[string][]rbacv1.Subject{}
+	for _, grant := range nc.Spec.Access {
+		role := grant.Role
+		if role == "" {
+			role = "view"
+		}
+		access[role] = append(access[role], subject(grant.Kind, grant.Name, grant.Namespace, namespaceName))
+	}
+	bindings := append(groupedBindings(namespaceName, roleBindingPrefix+"owner-", owners),
+		groupedBindings(namespaceName, roleBindingPrefix+"role-", access)...)
+	for _, binding := range bindings {
+		if err := ctrl.SetControllerReference(nc, binding, r.Scheme); err != nil {
+			return nil, err
+		}
+	}
+	return bindings, nil
+}
+
+// groupedBindings builds one RoleBinding named prefix<role> per role of subjects,
+// sorted by role
+func groupedBindings(namespaceName, prefix string, subjects map[string][]rbacv1.Subject) []*rbacv1.RoleBinding {
+	roles := make([]string, 0, len(subjects))
+	for role := range subjects {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	bindings := make([]*rbacv1.RoleBinding, 0, len(roles))
+	for _, role := range roles {
+		bindings = append(bindings, roleBinding(namespaceName, prefix+role, role, subjects[role]...))
+	}
+	return bindings
+}
+
+// roleBinding builds a RoleBinding of the ClusterRole role to subjects
+func roleBinding(namespaceName, name, role string, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespaceName,
+		},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+		Subjects: subjects,
+	}
+}
+
+// applyAccess applies the RoleBindings of the owners and of access, and deletes
+// the ones left from a previous owner or role
+func (r *NamespaceconfigReconciler) applyAccess(ctx context.Context, p *provisioning) *stepError {
+	conditions := []string{namespaceconfigv2.ConditionAccessGranted}
+	bindings, err := r.nsRoleBindings(p.nc, p.namespace.GetName())
+	if err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	desired := sets.New[string]()
+	for _, binding := range bindings {
+		desired.Insert(binding.GetName())
+		if err := r.applyChild(ctx, p.nc, binding, p.provisioned); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+
+	live := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, live, client.InNamespace(p.namespace.GetName())); err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	for i := range live.Items {
+		binding := &live.Items[i]
+		if !metav1.IsControlledBy(binding, p.nc) || desired.Has(binding.GetName()) {
+			continue
+		}
+		util.Logs.Info("Deleting RoleBinding ", binding.GetName(), " no longer granted by Namespaceconfig ", p.nc.GetName())
+		if err := r.Delete(ctx, binding); err != nil && !errors.IsNotFound(err) {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+	setCondition(p.nc, namespaceconfigv2.ConditionAccessGranted, metav1.ConditionTrue, "Applied",
+		"RoleBindings of the owners and of access are applied")
+	return nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+const (
+	// claimAnnotation holds the UID of the Namespaceconfig owning a namespace
+	claimAnnotation = namespaceconfigv2.ClaimAnnotation
+	// managedByAnnotation marks namespaces created by the operator
+	managedByAnnotation = "managed-by"
+	managedByValue      = "namespaceconfig.myoperator.io"
+)
+
+// isClaimedBy reports whether the namespace belongs to the Namespaceconfig.
+// Namespaces without a claim that were created by the operator before claims
+// existed are adopted by the Namespaceconfig that created them.
+func isClaimedBy(ns *corev1.Namespace, nc *namespaceconfigv2.Namespaceconfig) bool {
+	annotations := ns.GetAnnotations()
+	if uid, ok := annotations[claimAnnotation]; ok {
+		return uid == string(nc.GetUID())
+	}
+	if annotations[managedByAnnotation] != managedByValue {
+		return false
+	}
+	name, ok := annotations[namespaceconfigAnnotation]
+	return !ok || name == nc.GetName()
+}
+
+// claimConflict describes why a namespace not claimed by a Namespaceconfig cannot be managed by it
+func claimConflict(ns *corev1.Namespace) string {
+	annotations := ns.GetAnnotations()
+	if owner, ok := annotations[namespaceconfigAnnotation]; ok && annotations[managedByAnnotation] == managedByValue {
+		return fmt.Sprintf("Namespace %s is already claimed by Namespaceconfig %s", ns.GetName(), owner)
+	}
+	return fmt.Sprintf("Namespace %s already exists and is not managed by the operator", ns.GetName())
+}
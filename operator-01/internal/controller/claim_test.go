@@ -0,0 +1,60 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace claims", func() {
+	nc := testNamespaceconfig("payments", "pay", "dev")
+	namespace := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-dev", Annotations: annotations}}
+	}
+
+	DescribeTable("isClaimedBy and claimConflict",
+		func(annotations map[string]string, claimed bool, conflict string) {
+			ns := namespace(annotations)
+			Expect(isClaimedBy(ns, nc)).To(Equal(claimed))
+			Expect(claimConflict(ns)).To(Equal(conflict))
+		},
+		Entry("a claim with the UID of the Namespaceconfig",
+			map[string]string{
+				claimAnnotation:           "payments-uid",
+				managedByAnnotation:       managedByValue,
+				namespaceconfigAnnotation: "payments",
+			},
+			true, "Namespace pay-dev is already claimed by Namespaceconfig payments"),
+		Entry("a claim with the UID of a recreated Namespaceconfig of the same name",
+			map[string]string{
+				claimAnnotation:           "other-uid",
+				managedByAnnotation:       managedByValue,
+				namespaceconfigAnnotation: "payments",
+			},
+			false, "Namespace pay-dev is already claimed by Namespaceconfig payments"),
+		Entry("a claim of another Namespaceconfig",
+			map[string]string{
+				claimAnnotation:           "other-uid",
+				managedByAnnotation:       managedByValue,
+				namespaceconfigAnnotation: "billing",
+			},
+			false, "Namespace pay-dev is already claimed by Namespaceconfig billing"),
+		Entry("a legacy managed namespace without a name annotation",
+			map[string]string{managedByAnnotation: managedByValue},
+			true, "Namespace pay-dev already exists and is not managed by the operator"),
+		Entry("a legacy managed namespace of the Namespaceconfig",
+			map[string]string{managedByAnnotation: managedByValue, namespaceconfigAnnotation: "payments"},
+			true, "Namespace pay-dev is already claimed by Namespaceconfig payments"),
+		Entry("a legacy managed namespace of another Namespaceconfig",
+			map[string]string{managedByAnnotation: managedByValue, namespaceconfigAnnotation: "billing"},
+			false, "Namespace pay-dev is already claimed by Namespaceconfig billing"),
+		Entry("an unmanaged namespace",
+			nil,
+			false, "Namespace pay-dev already exists and is not managed by the operator"),
+		Entry("an unmanaged namespace naming the Namespaceconfig",
+			map[string]string{namespaceconfigAnnotation: "payments"},
+			false, "Namespace pay-dev already exists and is not managed by the operator"),
+	)
+})
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+const (
+	// fieldOwner is the field manager used for every server-side apply of the operator
+	fieldOwner = client.FieldOwner("namespaceconfig-operator")
+	// namespaceconfigAnnotation names the Namespaceconfig that manages a namespace
+	namespaceconfigAnnotation = namespaceconfigv2.NamespaceconfigAnnotation
+	// appliedAnnotation records a hash of the desired state an object was last applied with.
+	// A change to an object whose desired state did not change can only come from outside the
+	// operator, whatever input changed it: the Namespaceconfig, a NamespaceSizeProfile, a
+	// NamespaceTemplate, a replication source or a flag of the manager.
+	appliedAnnotation = "namespaceconfig.myoperator.io/applied"
+)
+
+// applyChild server-side applies obj, which must have its TypeMeta set, and
+// converges the live object to it. expected tells whether the object was
+// applied before. When the live object was changed or deleted outside of the
+// operator the correction is recorded as an event and a metric.
+func (r *NamespaceconfigReconciler) applyChild(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, obj client.Object, expected bool) error {
+	log := util.Logs
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	applied, err := desiredHash(obj)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[appliedAnnotation] = applied
+	obj.SetAnnotations(annotations)
+
+	live, err := r.newLike(obj)
+	if err != nil {
+		return err
+	}
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), live)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if err := r.Patch(ctx, obj, client.Apply, fieldOwner, client.ForceOwnership); err != nil {
+		return err
+	}
+
+	switch {
+	case !exists && expected:
+		r.recordDrift(nc, kind, obj.GetName(), "was deleted outside of the operator and has been recreated")
+	case !exists:
+		log.Info(kind, " ", obj.GetName(), " created")
+	case live.GetResourceVersion() != obj.GetResourceVersion():
+		if live.GetAnnotations()[appliedAnnotation] == applied {
+			r.recordDrift(nc, kind, obj.GetName(), "was changed outside of the operator and has been restored")
+		} else {
+			log.Info(kind, " ", obj.GetName(), " of Namespaceconfig ", nc.GetName(), " updated")
+		}
+	}
+	return nil
+}
+
+// desiredHash returns a hash of the desired state of obj, without the
+// annotation the hash is recorded in
+func desiredHash(obj client.Object) (string, error) {
+	desired, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return "", fmt.Errorf("%T is not a Kubernetes object", obj)
+	}
+	annotations := desired.GetAnnotations()
+	delete(annotations, appliedAnnotation)
+	desired.SetAnnotations(annotations)
+	content, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newLike returns an empty object of the kind of obj. Objects of kinds unknown
+// to the scheme, such as the ones of NamespaceTemplates, are unstructured.
+func (r *NamespaceconfigReconciler) newLike(obj client.Object) (client.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		return live, nil
+	}
+	newObj, err := r.Scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	live, ok := newObj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a Kubernetes object", gvk)
+	}
+	return live, nil
+}
+
+// recordDrift reports a corrected drift of a managed object
+func (r *NamespaceconfigReconciler) recordDrift(nc *namespaceconfigv2.Namespaceconfig, kind, name, message string) {
+	util.Logs.Info(kind, " ", name, " of Namespaceconfig ", nc.GetName(), " ", message)
+	driftCorrections.WithLabelValues(nc.GetName(), kind).Inc()
+	if r.Recorder != nil {
+		r.Recorder.Eventf(nc, corev1.EventTypeWarning, "DriftCorrected", "%s %s %s", kind, name, message)
+	}
+}
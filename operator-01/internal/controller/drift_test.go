@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Drift", func() {
+	const nsname = "dft-dev"
+
+	It("counts changes outside of the operator only, not a NamespaceSizeProfile edit", func() {
+		ctx := context.Background()
+		nc := testNamespaceconfig("drift", "dft", "dev")
+		nc.SetGeneration(1)
+		recorder := record.NewFakeRecorder(10)
+		r := fakeReconciler()
+		r.Recorder = recorder
+		limitsOf := func(max string) corev1.LimitRangeSpec {
+			return corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type: corev1.LimitTypeContainer,
+				Max:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(max)},
+			}}}
+		}
+		apply := func(spec corev1.LimitRangeSpec) {
+			limits, err := r.nsLimits(nc, "s", spec, nsname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.applyChild(ctx, nc, limits, true)).To(Succeed())
+		}
+		Expect(r.Create(ctx, &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "s"), Namespace: nsname}})).To(Succeed())
+		apply(limitsOf("1"))
+		Expect(recorder.Events).To(BeEmpty())
+
+		By("Editing the profile without a new generation of the Namespaceconfig")
+		apply(limitsOf("2"))
+		Expect(recorder.Events).To(BeEmpty())
+
+		By("Editing the LimitRange outside of the operator")
+		live := &corev1.LimitRange{}
+		Expect(r.Get(ctx, client.ObjectKey{Name: childName(nsname, "s"), Namespace: nsname}, live)).To(Succeed())
+		live.Spec = limitsOf("8")
+		Expect(r.Update(ctx, live)).To(Succeed())
+		apply(limitsOf("2"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("DriftCorrected LimitRange")))
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(live), live)).To(Succeed())
+		Expect(live.Spec.Limits[0].Max.Cpu().String()).To(Equal("2"))
+	})
+})
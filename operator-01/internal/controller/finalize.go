@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+const (
+	// finalizerName holds a Namespaceconfig until its namespace is gone
+	finalizerName = "namespaceconfig.myoperator.io/finalizer"
+	// terminationPoll is how often a terminating namespace is checked
+	terminationPoll = 10 * time.Second
+	// stuckAfter is how long a namespace may be terminating before it is reported as stuck
+	stuckAfter = 5 * time.Minute
+)
+
+// namespaceDeletionConditions are the namespace conditions set by the namespace
+// controller while content or finalizers block the deletion of a namespace
+var namespaceDeletionConditions = []corev1.NamespaceConditionType{
+	corev1.NamespaceDeletionDiscoveryFailure,
+	corev1.NamespaceDeletionGVParsingFailure,
+	corev1.NamespaceDeletionContentFailure,
+	corev1.NamespaceContentRemaining,
+	corev1.NamespaceFinalizersRemaining,
+}
+
+// finalize applies the DeletionPolicy to the namespace claimed by the
+// Namespaceconfig. With Delete the finalizer is kept until the namespace is
+// actually gone. Errors leave the finalizer in place so that the deletion is retried.
+func (r *NamespaceconfigReconciler) finalize(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, original *namespaceconfigv2.NamespaceconfigStatus, namespaceName string) (ctrl.Result, error) {
+	log := util.Logs
+	if !controllerutil.ContainsFinalizer(nc, finalizerName) {
+		return ctrl.Result{}, nil
+	}
+	// The webhook rejects the deletion of protected Namespaceconfigs. This
+	// catches the ones deleted while it was not running.
+	if config.DeletionProtected(nc, r.ProtectedEnvironments) {
+		return r.blocked(ctx, nc, original, "DeletionProtected",
+			"Namespaceconfig "+nc.GetName()+" is protected against deletion, set spec.deletionProtection to false to delete it",
+			namespaceconfigv2.ConditionDeleting)
+	}
+	setCondition(nc, namespaceconfigv2.ConditionReady, metav1.ConditionFalse, "Deleting", "Namespaceconfig is being deleted")
+
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
+	switch {
+	case errors.IsNotFound(err):
+		log.Info("Namespace ", namespaceName, " is gone")
+		return ctrl.Result{}, r.removeFinalizer(ctx, nc)
+	case err != nil:
+		log.Error("Failed to get Namespace ", namespaceName, ". Error: ", err)
+		return r.failed(ctx, nc, original, "DeletionFailed", err, namespaceconfigv2.ConditionDeleting)
+	case !isClaimedBy(namespace, nc):
+		log.Info("Namespace ", namespaceName, " is not claimed by Namespaceconfig ", nc.GetName(), ", leaving it in place")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(nc, corev1.EventTypeNormal, "DeletionSkipped", "%s, it is not deleted", claimConflict(namespace))
+		}
+		return ctrl.Result{}, r.removeFinalizer(ctx, nc)
+	}
+
+	if policy := r.deletionPolicy(nc); policy != namespaceconfigv2.DeletionPolicyDelete {
+		if err := r.release(ctx, nc, namespace, policy == namespaceconfigv2.DeletionPolicyRetain); err != nil {
+			log.Error("Failed to release Namespace ", namespaceName, ". Error: ", err)
+			return r.failed(ctx, nc, original, "DeletionFailed", err, namespaceconfigv2.ConditionDeleting)
+		}
+		log.Info("Namespace ", namespaceName, " kept with DeletionPolicy ", policy)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(nc, corev1.EventTypeNormal, "Namespace"+string(policy)+"ed",
+				"Namespace %s is kept with DeletionPolicy %s", namespaceName, policy)
+		}
+		return ctrl.Result{}, r.removeFinalizer(ctx, nc)
+	}
+
+	if namespace.GetDeletionTimestamp().IsZero() {
+		hazards, err := r.deletionHazards(ctx, namespaceName)
+		if err != nil {
+			log.Error("Failed to check the content of Namespace ", namespaceName, ". Error: ", err)
+			return r.failed(ctx, nc, original, "SafetyCheckFailed", err, namespaceconfigv2.ConditionDeleting)
+		}
+		if len(hazards) > 0 {
+			message := fmt.Sprintf("Namespace %s is not empty: %s", namespaceName, strings.Join(hazards, ", "))
+			if !forceDelete(nc) {
+				return r.blocked(ctx, nc, original, "DeletionBlocked",
+					message+". Set the annotation "+forceDeleteAnnotation+"=true on the Namespaceconfig to delete it anyway",
+					namespaceconfigv2.ConditionDeleting)
+			}
+			log.Info(message, ", deleting it anyway as Namespaceconfig ", nc.GetName(), " is annotated with ", forceDeleteAnnotation)
+			if r.Recorder != nil {
+				r.Recorder.Event(nc, corev1.EventTypeWarning, "ForceDelete", message+", deleting it anyway")
+			}
+		}
+		log.Info("Deleting Namespace ", namespaceName)
+		if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
+			log.Error("Failed to delete Namespace ", namespaceName, ". Error: ", err)
+			return r.failed(ctx, nc, original, "DeletionFailed", err, namespaceconfigv2.ConditionDeleting)
+		}
+		setCondition(nc, namespaceconfigv2.ConditionDeleting, metav1.ConditionTrue, "NamespaceTerminating", "Namespace "+namespaceName+" is terminating")
+		return ctrl.Result{RequeueAfter: terminationPoll}, r.updateStatus(ctx, nc, original)
+	}
+
+	terminating := time.Since(namespace.GetDeletionTimestamp().Time)
+	if blockers := deletionBlockers(namespace); terminating > stuckAfter && len(blockers) > 0 {
+		message := fmt.Sprintf("Namespace %s is stuck in Terminating for %s: %s",
+			namespaceName, terminating.Round(time.Second), strings.Join(blockers, "; "))
+		log.Error(message, " for Namespaceconfig ", nc.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Event(nc, corev1.EventTypeWarning, "NamespaceStuck", message)
+		}
+		setCondition(nc, namespaceconfigv2.ConditionDeleting, metav1.ConditionTrue, "NamespaceStuck", message)
+		return ctrl.Result{RequeueAfter: blockedRequeue}, r.updateStatus(ctx, nc, original)
+	}
+	log.Info("Waiting for Namespace ", namespaceName, " to terminate")
+	setCondition(nc, namespaceconfigv2.ConditionDeleting, metav1.ConditionTrue, "NamespaceTerminating", "Namespace "+namespaceName+" is terminating")
+	return ctrl.Result{RequeueAfter: terminationPoll}, r.updateStatus(ctx, nc, original)
+}
+
+// deletionPolicy returns the DeletionPolicy of the Namespaceconfig, or the
+// cluster default of its environment when it sets none
+func (r *NamespaceconfigReconciler) deletionPolicy(nc *namespaceconfigv2.Namespaceconfig) namespaceconfigv2.DeletionPolicy {
+	if nc.Spec.DeletionPolicy != "" {
+		return nc.Spec.DeletionPolicy
+	}
+	return r.DeletionPolicies.For(nc.Spec.Environment)
+}
+
+// release detaches the namespace and its children from the Namespaceconfig so
+// that they are not garbage collected with it. With strip the metadata of the
+// operator is removed as well, including the labels and annotations it set on
+// the namespace, and the namespace is no longer managed.
+func (r *NamespaceconfigReconciler) release(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespace *corev1.Namespace, strip bool) error {
+	limits := &corev1.LimitRangeList{}
+	if err := r.List(ctx, limits, client.InNamespace(namespace.GetName())); err != nil {
+		return err
+	}
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(namespace.GetName())); err != nil {
+		return err
+	}
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(namespace.GetName())); err != nil {
+		return err
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, bindings, client.InNamespace(namespace.GetName())); err != nil {
+		return err
+	}
+	objects := []client.Object{}
+	for i := range limits.Items {
+		if metav1.IsControlledBy(&limits.Items[i], nc) {
+			objects = append(objects, &limits.Items[i])
+		}
+	}
+	for i := range quotas.Items {
+		if metav1.IsControlledBy(&quotas.Items[i], nc) {
+			objects = append(objects, &quotas.Items[i])
+		}
+	}
+	for i := range policies.Items {
+		if metav1.IsControlledBy(&policies.Items[i], nc) {
+			objects = append(objects, &policies.Items[i])
+		}
+	}
+	for i := range bindings.Items {
+		if metav1.IsControlledBy(&bindings.Items[i], nc) {
+			objects = append(objects, &bindings.Items[i])
+		}
+	}
+	for _, ref := range nc.Status.TemplateObjects {
+		obj, err := r.templateObject(ctx, nc, namespace.GetName(), ref)
+		if err != nil {
+			return err
+		}
+		if obj != nil {
+			objects = append(objects, obj)
+		}
+	}
+	replicas, err := r.replicasOf(ctx, nc, namespace.GetName())
+	if err != nil {
+		return err
+	}
+	for _, obj := range replicas {
+		objects = append(objects, obj)
+	}
+	if strip {
+		objects = append(objects, namespace)
+	}
+	for _, obj := range objects {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		owners := []metav1.OwnerReference{}
+		for _, owner := range obj.GetOwnerReferences() {
+			if owner.UID != nc.GetUID() {
+				owners = append(owners, owner)
+			}
+		}
+		obj.SetOwnerReferences(owners)
+		if strip {
+			annotations := obj.GetAnnotations()
+			for _, key := range []string{managedByAnnotation, namespaceconfigAnnotation, claimAnnotation, appliedAnnotation, ownersAnnotation} {
+				delete(annotations, key)
+			}
+			obj.SetAnnotations(annotations)
+			if ns, ok := obj.(*corev1.Namespace); ok {
+				stripNamespace(nc, ns)
+			}
+		}
+		if err := r.Patch(ctx, obj, patch); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripNamespace removes the labels and annotations the operator sets on the
+// namespace of nc: owner, env, the Pod Security Admission labels and the custom
+// labels and annotations of the Namespaceconfig
+func stripNamespace(nc *namespaceconfigv2.Namespaceconfig, namespace *corev1.Namespace) {
+	labels := namespace.GetLabels()
+	delete(labels, "owner")
+	delete(labels, "env")
+	for _, mode := range podSecurityModes {
+		delete(labels, podSecurityLabelPrefix+mode)
+		delete(labels, podSecurityLabelPrefix+mode+"-version")
+	}
+	removeCustom(labels, nc.Spec.NamespaceLabels)
+	namespace.SetLabels(labels)
+	annotations := namespace.GetAnnotations()
+	removeCustom(annotations, nc.Spec.NamespaceAnnotations)
+	namespace.SetAnnotations(annotations)
+}
+
+// removeFinalizer lets the Namespaceconfig go once its namespace is dealt with
+func (r *NamespaceconfigReconciler) removeFinalizer(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig) error {
+	log := util.Logs
+	log.Info("Removing finalizer ", finalizerName, " from Namespaceconfig ", nc.GetName())
+	controllerutil.RemoveFinalizer(nc, finalizerName)
+	if err := r.Update(ctx, nc); err != nil {
+		log.Error("Unable to remove finalizer and update Namespaceconfig ", err)
+		return err
+	}
+	log.Info("Finalizer removed from Namespaceconfig ", nc.GetName())
+	return nil
+}
+
+// deletionBlockers lists what the namespace controller reports as blocking the
+// deletion of a namespace, such as remaining resources and their finalizers
+func deletionBlockers(ns *corev1.Namespace) []string {
+	blockers := []string{}
+	for _, conditionType := range namespaceDeletionConditions {
+		for _, condition := range ns.Status.Conditions {
+			if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+				blockers = append(blockers, condition.Message)
+			}
+		}
+	}
+	return blockers
+}
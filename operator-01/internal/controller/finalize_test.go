@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("Release", func() {
+	const nsname = "rel-prod"
+
+	DescribeTable("keeps the children of the namespace when the Namespaceconfig goes",
+		func(policy namespaceconfigv2.DeletionPolicy) {
+			ctx := context.Background()
+			nc := testNamespaceconfig("release", "rel", "prod")
+			nc.Spec.DeletionPolicy = policy
+			nc.Spec.NamespaceLabels = map[string]string{"team": "release"}
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: nsname,
+				Annotations: map[string]string{
+					managedByAnnotation:       managedByValue,
+					namespaceconfigAnnotation: nc.GetName(),
+					claimAnnotation:           string(nc.GetUID()),
+				},
+				Labels: map[string]string{
+					"owner":                            "release-team",
+					"env":                              "prod",
+					podSecurityLabelPrefix + "enforce": "restricted",
+					"team":                             "release",
+					"kubernetes.io/metadata.name":      nsname,
+				},
+			}}
+			children := controlled(nc,
+				&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "s"), Namespace: nsname}},
+				&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "s"), Namespace: nsname}},
+				&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: networkPolicyPrefix + "default-deny", Namespace: nsname}},
+				roleBinding(nsname, roleBindingPrefix+"owner-admin", "admin"),
+				roleBinding(nsname, roleBindingPrefix+"role-view", "view"),
+			)
+			r := fakeReconciler(append(children, namespace)...)
+
+			Expect(r.release(ctx, nc, namespace, policy == namespaceconfigv2.DeletionPolicyRetain)).To(Succeed())
+			for _, obj := range children {
+				Expect(r.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+				Expect(obj.GetOwnerReferences()).To(BeEmpty(), obj.GetName())
+			}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)).To(Succeed())
+			if policy == namespaceconfigv2.DeletionPolicyRetain {
+				Expect(namespace.GetAnnotations()).NotTo(HaveKey(claimAnnotation))
+				Expect(namespace.GetAnnotations()).NotTo(HaveKey(managedByAnnotation))
+				Expect(namespace.GetLabels()).To(Equal(map[string]string{"kubernetes.io/metadata.name": nsname}))
+			} else {
+				Expect(namespace.GetAnnotations()).To(HaveKeyWithValue(claimAnnotation, string(nc.GetUID())))
+				Expect(namespace.GetLabels()).To(HaveLen(5))
+			}
+			binding := &rbacv1.RoleBinding{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: roleBindingPrefix + "owner-admin", Namespace: nsname}, binding)).To(Succeed())
+			Expect(binding.RoleRef.Name).To(Equal("admin"))
+		},
+		Entry("with Retain", namespaceconfigv2.DeletionPolicyRetain),
+		Entry("with Orphan", namespaceconfigv2.DeletionPolicyOrphan),
+	)
+})
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// Fixtures of the specs that run on a fake client and need no API server
+
+// newFakeScheme returns a scheme holding the built-in types and the types of the operator
+func newFakeScheme() *apiruntime.Scheme {
+	fakeScheme := apiruntime.NewScheme()
+	Expect(scheme.AddToScheme(fakeScheme)).To(Succeed())
+	Expect(namespaceconfigv1.AddToScheme(fakeScheme)).To(Succeed())
+	Expect(namespaceconfigv2.AddToScheme(fakeScheme)).To(Succeed())
+	return fakeScheme
+}
+
+// newFakeClient returns a builder of a fake client holding objs
+func newFakeClient(objs ...client.Object) *fake.ClientBuilder {
+	return fake.NewClientBuilder().WithScheme(newFakeScheme()).WithObjects(objs...)
+}
+
+// fakeReconciler returns a NamespaceconfigReconciler on a fake client holding
+// objs, for the parts of a reconcile that need no API server
+func fakeReconciler(objs ...client.Object) *NamespaceconfigReconciler {
+	fakeClient := newFakeClient(objs...).Build()
+	return &NamespaceconfigReconciler{Client: fakeClient, Scheme: fakeClient.Scheme()}
+}
+
+// testNamespaceconfig returns a Namespaceconfig with a UID derived from its
+// name, for the namespace abbreviation-environment
+func testNamespaceconfig(name, abbreviation, environment string) *namespaceconfigv2.Namespaceconfig {
+	return &namespaceconfigv2.Namespaceconfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid")},
+		Spec: namespaceconfigv2.NamespaceconfigSpec{
+			Abbreviation: abbreviation,
+			Environment:  environment,
+		},
+	}
+}
+
+// controlled makes nc the controller of objs
+func controlled(nc *namespaceconfigv2.Namespaceconfig, objs ...client.Object) []client.Object {
+	fakeScheme := newFakeScheme()
+	for _, obj := range objs {
+		Expect(ctrl.SetControllerReference(nc, obj, fakeScheme)).To(Succeed())
+	}
+	return objs
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// driftCorrections counts managed objects that were changed or deleted
+	// outside of the operator and converged back to the Namespaceconfig
+	driftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "namespaceconfig_drift_corrections_total",
+			Help: "Number of managed objects restored after a change outside of the operator",
+		},
+		[]string{"namespaceconfig", "kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftCorrections)
+}
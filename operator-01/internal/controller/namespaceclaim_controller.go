@@ -0,0 +1,291 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// claimFinalizerName holds a NamespaceClaim until its Namespaceconfig is released
+const claimFinalizerName = "namespaceconfig.myoperator.io/claim"
+
+// NamespaceClaimReconciler binds NamespaceClaims to Namespaceconfigs
+type NamespaceClaimReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceclaims,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceclaims/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceclaims/finalizers,verbs=update
+
+// Reconcile binds a NamespaceClaim to the Namespaceconfig reserved for it, or
+// to a Namespaceconfig provisioned from its spec, and releases the
+// Namespaceconfig when the claim is deleted.
+func (r *NamespaceClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := util.Logs
+	claim := &namespaceconfigv2.NamespaceClaim{}
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("NamespaceClaim ", req.NamespacedName, " not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error("Failed to get NamespaceClaim ", req.NamespacedName, ". Error: ", err)
+		return ctrl.Result{}, err
+	}
+	original := claim.Status.DeepCopy()
+
+	if !claim.GetDeletionTimestamp().IsZero() {
+		return r.releaseClaim(ctx, claim, original)
+	}
+	if !controllerutil.ContainsFinalizer(claim, claimFinalizerName) {
+		controllerutil.AddFinalizer(claim, claimFinalizerName)
+		if err := r.Update(ctx, claim); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	name := config.ClaimedName(claim)
+	nc := &namespaceconfigv2.Namespaceconfig{}
+	err := r.Get(ctx, client.ObjectKey{Name: name}, nc)
+	switch {
+	case errors.IsNotFound(err) && (claim.Status.Phase == namespaceconfigv2.ClaimBound || claim.Status.Phase == namespaceconfigv2.ClaimLost):
+		return r.unbound(ctx, claim, original, namespaceconfigv2.ClaimLost, "NamespaceconfigLost",
+			"Namespaceconfig "+name+" bound to the claim is gone")
+	case errors.IsNotFound(err) && claim.Spec.NamespaceconfigName != "":
+		return r.unbound(ctx, claim, original, namespaceconfigv2.ClaimPending, "NamespaceconfigNotFound",
+			"Namespaceconfig "+name+" does not exist")
+	case errors.IsNotFound(err):
+		if err := r.provisionClaim(ctx, claim, name); err != nil {
+			// rejections of the admission webhooks are reported on the claim
+			log.Error("Failed to provision Namespaceconfig ", name, " for NamespaceClaim ", req.NamespacedName, ". Error: ", err)
+			return r.unbound(ctx, claim, original, namespaceconfigv2.ClaimPending, "ProvisioningFailed", err.Error())
+		}
+		return ctrl.Result{}, nil
+	case err != nil:
+		log.Error("Failed to get Namespaceconfig ", name, ". Error: ", err)
+		return ctrl.Result{}, err
+	}
+
+	ref := nc.Spec.ClaimRef
+	if ref == nil || ref.Namespace != claim.GetNamespace() || ref.Name != claim.GetName() || (ref.UID != "" && ref.UID != claim.GetUID()) {
+		// like a PersistentVolume, a Namespaceconfig is bound only to the claim it is reserved for
+		return r.unbound(ctx, claim, original, namespaceconfigv2.ClaimPending, "NotReserved",
+			"Namespaceconfig "+name+" is not reserved for this claim")
+	}
+	if err := r.syncClaimed(ctx, claim, nc); err != nil {
+		log.Error("Failed to bind Namespaceconfig ", name, " to NamespaceClaim ", req.NamespacedName, ". Error: ", err)
+		return ctrl.Result{}, err
+	}
+
+	claim.Status.Phase = namespaceconfigv2.ClaimBound
+	claim.Status.NamespaceconfigName = name
+	claim.Status.NamespaceName = nc.Status.NamespaceName
+	setClaimCondition(claim, namespaceconfigv2.ConditionBound, metav1.ConditionTrue, "Bound", "Bound to Namespaceconfig "+name)
+	if ready := meta.FindStatusCondition(nc.Status.Conditions, namespaceconfigv2.ConditionReady); ready != nil {
+		setClaimCondition(claim, namespaceconfigv2.ConditionReady, ready.Status, ready.Reason, ready.Message)
+	} else {
+		setClaimCondition(claim, namespaceconfigv2.ConditionReady, metav1.ConditionUnknown, "Provisioning", "Namespaceconfig "+name+" is being provisioned")
+	}
+	if original.Phase != namespaceconfigv2.ClaimBound && r.Recorder != nil {
+		r.Recorder.Event(claim, corev1.EventTypeNormal, "Bound", "Bound to Namespaceconfig "+name)
+	}
+	return ctrl.Result{}, r.updateClaimStatus(ctx, claim, original)
+}
+
+// provisionClaim creates the Namespaceconfig of a claim from its spec. It goes
+// through the admission webhooks, which enforce the same policy as for
+// Namespaceconfigs created by cluster admins.
+func (r *NamespaceClaimReconciler) provisionClaim(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, name string) error {
+	nc := &namespaceconfigv2.Namespaceconfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				namespaceconfigv2.ProvisionedByAnnotation: claim.GetNamespace() + "/" + claim.GetName(),
+			},
+		},
+		Spec: namespaceconfigv2.NamespaceconfigSpec{
+			Environment:  claim.Spec.Environment,
+			Abbreviation: claim.Spec.Abbreviation,
+			Owners:       claim.Spec.Owners,
+			Size:         claim.Spec.Size,
+			ClaimRef: &namespaceconfigv2.ClaimReference{
+				Namespace: claim.GetNamespace(),
+				Name:      claim.GetName(),
+				UID:       claim.GetUID(),
+			},
+		},
+	}
+	if err := r.Create(ctx, nc); err != nil {
+		return err
+	}
+	util.Logs.Info("Namespaceconfig ", name, " provisioned for NamespaceClaim ", claim.GetNamespace(), "/", claim.GetName())
+	if r.Recorder != nil {
+		r.Recorder.Event(claim, corev1.EventTypeNormal, "Provisioned", "Namespaceconfig "+name+" provisioned")
+	}
+	return nil
+}
+
+// syncClaimed records the UID of the claim on the claimRef of a reserved
+// Namespaceconfig, and keeps the size and owners of a provisioned one in line
+// with the claim
+func (r *NamespaceClaimReconciler) syncClaimed(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, nc *namespaceconfigv2.Namespaceconfig) error {
+	original := nc.DeepCopy()
+	nc.Spec.ClaimRef.UID = claim.GetUID()
+	if _, ok := nc.GetAnnotations()[namespaceconfigv2.ProvisionedByAnnotation]; ok {
+		if claim.Spec.Size != "" {
+			nc.Spec.Size = claim.Spec.Size
+		}
+		if len(claim.Spec.Owners) > 0 {
+			nc.Spec.Owners = claim.Spec.Owners
+		}
+	}
+	if equality.Semantic.DeepEqual(original.Spec, nc.Spec) {
+		return nil
+	}
+	return r.Patch(ctx, nc, client.MergeFrom(original))
+}
+
+// releaseClaim deletes the Namespaceconfig provisioned for a deleted claim,
+// under its DeletionPolicy, and frees a reserved one by clearing its claimRef.
+// The finalizer is removed once the Namespaceconfig is released. A deletion
+// rejected by the webhook, as for a protected Namespaceconfig, is reported on
+// the claim and checked again after a while.
+func (r *NamespaceClaimReconciler) releaseClaim(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, original *namespaceconfigv2.NamespaceClaimStatus) (ctrl.Result, error) {
+	log := util.Logs
+	if !controllerutil.ContainsFinalizer(claim, claimFinalizerName) {
+		return ctrl.Result{}, nil
+	}
+	nc := &namespaceconfigv2.Namespaceconfig{}
+	err := r.Get(ctx, client.ObjectKey{Name: config.ClaimedName(claim)}, nc)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil && nc.Spec.ClaimRef != nil && nc.Spec.ClaimRef.UID == claim.GetUID() {
+		if _, ok := nc.GetAnnotations()[namespaceconfigv2.ProvisionedByAnnotation]; ok {
+			log.Info("Deleting Namespaceconfig ", nc.GetName(), " of deleted NamespaceClaim ", claim.GetNamespace(), "/", claim.GetName())
+			err := r.Delete(ctx, nc)
+			switch {
+			case errors.IsForbidden(err):
+				return r.releaseBlocked(ctx, claim, original,
+					"Namespaceconfig "+nc.GetName()+" of the claim cannot be deleted: "+err.Error())
+			case err != nil && !errors.IsNotFound(err):
+				return ctrl.Result{}, err
+			}
+		} else {
+			log.Info("Releasing Namespaceconfig ", nc.GetName(), " of deleted NamespaceClaim ", claim.GetNamespace(), "/", claim.GetName())
+			patch := client.MergeFrom(nc.DeepCopy())
+			nc.Spec.ClaimRef = nil
+			if err := r.Patch(ctx, nc, patch); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+	controllerutil.RemoveFinalizer(claim, claimFinalizerName)
+	return ctrl.Result{}, r.Update(ctx, claim)
+}
+
+// releaseBlocked reports a deleted claim whose Namespaceconfig cannot be
+// deleted on the Deleting and Ready conditions. Retrying right away cannot
+// help, it is checked again after a while or when its Namespaceconfig changes.
+func (r *NamespaceClaimReconciler) releaseBlocked(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, original *namespaceconfigv2.NamespaceClaimStatus, message string) (ctrl.Result, error) {
+	reason := "DeletionForbidden"
+	setClaimCondition(claim, namespaceconfigv2.ConditionDeleting, metav1.ConditionFalse, reason, message)
+	setClaimCondition(claim, namespaceconfigv2.ConditionReady, metav1.ConditionFalse, reason, message)
+	if deleting := meta.FindStatusCondition(original.Conditions, namespaceconfigv2.ConditionDeleting); deleting == nil || deleting.Reason != reason {
+		util.Logs.Error(message, " for NamespaceClaim ", claim.GetNamespace(), "/", claim.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Event(claim, corev1.EventTypeWarning, reason, message)
+		}
+	}
+	return ctrl.Result{RequeueAfter: blockedRequeue}, r.updateClaimStatus(ctx, claim, original)
+}
+
+// unbound reports a claim that is not bound with phase, reason and message.
+// It is checked again after a while, or when its Namespaceconfig changes.
+func (r *NamespaceClaimReconciler) unbound(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, original *namespaceconfigv2.NamespaceClaimStatus, phase namespaceconfigv2.ClaimPhase, reason, message string) (ctrl.Result, error) {
+	claim.Status.Phase = phase
+	claim.Status.NamespaceName = ""
+	setClaimCondition(claim, namespaceconfigv2.ConditionBound, metav1.ConditionFalse, reason, message)
+	setClaimCondition(claim, namespaceconfigv2.ConditionReady, metav1.ConditionFalse, reason, message)
+	if bound := meta.FindStatusCondition(original.Conditions, namespaceconfigv2.ConditionBound); bound == nil || bound.Reason != reason {
+		util.Logs.Error(message, " for NamespaceClaim ", claim.GetNamespace(), "/", claim.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Event(claim, corev1.EventTypeWarning, reason, message)
+		}
+	}
+	return ctrl.Result{RequeueAfter: blockedRequeue}, r.updateClaimStatus(ctx, claim, original)
+}
+
+// setClaimCondition sets a condition of the NamespaceClaim for its current generation
+func setClaimCondition(claim *namespaceconfigv2.NamespaceClaim, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: claim.GetGeneration(),
+	})
+}
+
+// updateClaimStatus writes the status of the NamespaceClaim when it differs from original
+func (r *NamespaceClaimReconciler) updateClaimStatus(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, original *namespaceconfigv2.NamespaceClaimStatus) error {
+	claim.Status.ObservedGeneration = claim.GetGeneration()
+	if equality.Semantic.DeepEqual(original, &claim.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, claim)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NamespaceClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	recoverPanic := true
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RecoverPanic: &recoverPanic}).
+		For(&namespaceconfigv2.NamespaceClaim{}).
+		Watches(&namespaceconfigv2.Namespaceconfig{}, handler.EnqueueRequestsFromMapFunc(namespaceconfigToClaim)).
+		Complete(r)
+}
+
+// namespaceconfigToClaim maps a Namespaceconfig to the claim it is reserved for
+func namespaceconfigToClaim(ctx context.Context, obj client.Object) []reconcile.Request {
+	nc, ok := obj.(*namespaceconfigv2.Namespaceconfig)
+	if !ok || nc.Spec.ClaimRef == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: nc.Spec.ClaimRef.Namespace, Name: nc.Spec.ClaimRef.Name}}}
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("NamespaceClaim controller", Ordered, func() {
+	BeforeAll(useTestCluster)
+
+	Context("NamespaceClaim of a tenant", func() {
+		It("Should provision and bind a Namespaceconfig", func() {
+			ctx := context.Background()
+			home := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-billing"}}
+			Expect(k8sClient.Create(ctx, home)).Should(Succeed())
+			claim := &namespaceconfigv2.NamespaceClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: home.GetName()},
+				Spec: namespaceconfigv2.NamespaceClaimSpec{
+					Environment:  "dev",
+					Abbreviation: "bil",
+					Size:         "s",
+				},
+			}
+			Expect(k8sClient.Create(ctx, claim)).Should(Succeed())
+			Eventually(func() namespaceconfigv2.ClaimPhase {
+				_ = k8sClient.Get(ctx, types.NamespacedName{Namespace: claim.GetNamespace(), Name: claim.GetName()}, claim)
+				return claim.Status.Phase
+			}, time.Second*30, time.Second*3).Should(Equal(namespaceconfigv2.ClaimBound))
+			Expect(claim.Status.NamespaceconfigName).To(Equal("team-billing-billing"))
+			Expect(meta.IsStatusConditionTrue(claim.Status.Conditions, namespaceconfigv2.ConditionBound)).To(BeTrue())
+
+			nc := &namespaceconfigv2.Namespaceconfig{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: claim.Status.NamespaceconfigName}, nc)).Should(Succeed())
+			Expect(nc.Spec.ClaimRef).NotTo(BeNil())
+			Expect(nc.Spec.ClaimRef.UID).To(Equal(claim.GetUID()))
+
+			By("Deleting the claim")
+			Expect(k8sClient.Delete(ctx, claim)).Should(Succeed())
+			Eventually(func() bool {
+				found := &namespaceconfigv2.Namespaceconfig{}
+				_ = k8sClient.Get(ctx, types.NamespacedName{Name: nc.GetName()}, found)
+				return !found.GetDeletionTimestamp().IsZero()
+			}, time.Second*30, time.Second*3).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NamespaceClaim release", func() {
+	It("Should report a Namespaceconfig whose deletion is forbidden", func() {
+		ctx := context.Background()
+		now := metav1.Now()
+		claim := &namespaceconfigv2.NamespaceClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "payments",
+				Namespace:         "team-payments",
+				UID:               types.UID("claim-uid"),
+				DeletionTimestamp: &now,
+				Finalizers:        []string{claimFinalizerName},
+			},
+			Spec: namespaceconfigv2.NamespaceClaimSpec{Environment: "prod", Abbreviation: "pay", Size: "s"},
+		}
+		nc := &namespaceconfigv2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "team-payments-payments",
+				Annotations: map[string]string{namespaceconfigv2.ProvisionedByAnnotation: "team-payments/payments"},
+			},
+			Spec: namespaceconfigv2.NamespaceconfigSpec{
+				ClaimRef: &namespaceconfigv2.ClaimReference{Namespace: "team-payments", Name: "payments", UID: claim.GetUID()},
+			},
+		}
+		protected := true
+		fakeClient := newFakeClient(claim, nc).
+			WithStatusSubresource(claim).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					if _, ok := obj.(*namespaceconfigv2.Namespaceconfig); ok && protected {
+						return errors.NewForbidden(namespaceconfigv2.GroupVersion.WithResource("namespaceconfigs").GroupResource(),
+							obj.GetName(), fmt.Errorf("the Namespaceconfig is protected against deletion"))
+					}
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &NamespaceClaimReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Recorder: recorder}
+		request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: claim.GetNamespace(), Name: claim.GetName()}}
+
+		result, err := r.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(blockedRequeue))
+		found := &namespaceconfigv2.NamespaceClaim{}
+		Expect(fakeClient.Get(ctx, request.NamespacedName, found)).To(Succeed())
+		Expect(found.GetFinalizers()).To(ContainElement(claimFinalizerName))
+		deleting := meta.FindStatusCondition(found.Status.Conditions, namespaceconfigv2.ConditionDeleting)
+		Expect(deleting).NotTo(BeNil())
+		Expect(deleting.Status).To(Equal(metav1.ConditionFalse))
+		Expect(deleting.Reason).To(Equal("DeletionForbidden"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("DeletionForbidden")))
+
+		By("Turning the protection off")
+		protected = false
+		_, err = r.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(fakeClient.Get(ctx, types.NamespacedName{Name: nc.GetName()}, nc))).To(BeTrue())
+	})
+})
@@ -18,41 +18,84 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
 	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
 )
 
 // NamespaceconfigReconciler reconciles a Namespaceconfig object
 type NamespaceconfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader reads from the API server without a cache
+	APIReader client.Reader
+	// DeletionPolicies are the cluster default DeletionPolicies per environment
+	DeletionPolicies config.DeletionPolicies
+	// DeletionDenyList are resources that block the deletion of a namespace containing any of them
+	DeletionDenyList config.GroupResources
+	// ProtectedEnvironments are the environments where DeletionProtection defaults to true
+	ProtectedEnvironments config.Environments
+	// NetworkPolicies are the NetworkPolicy bundles per environment
+	NetworkPolicies config.NetworkPolicyBundles
+	// IngressNamespace is the namespace of the ingress controller allowed into
+	// every namespace, no ingress controller is allowed when empty
+	IngressNamespace string
+	// PodSecurity are the Pod Security Standards enforced per environment
+	PodSecurity config.PodSecurityLevels
+	// PodSecurityVersion pins the version of the Pod Security Standards, latest when empty
+	PodSecurityVersion string
+	// ReplicationNamespace holds the Secrets and ConfigMaps replicated into
+	// the namespaces, nothing is replicated when empty
+	ReplicationNamespace string
 }
 
 //+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespaceconfigs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespacesizeprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=limitranges;resourcequotas,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims;services,verbs=list
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=admin;edit;view
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=namespaceconfig.myoperator.io,resources=namespacetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Namespaceconfig object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// Reconcile builds the desired namespace of a Namespaceconfig, with its labels
+// and annotations, and converges the cluster to it. A live Namespaceconfig gets
+// the finalizer and is provisioned: the provisioning steps resolve the size,
+// apply the namespace, its LimitRange, ResourceQuota, RoleBindings,
+// NetworkPolicies, templates and replicated objects, remove the objects of a
+// previous size and record the outcome in the status conditions. A
+// Namespaceconfig being deleted is finalized: its namespace is deleted, retained
+// or orphaned according to spec.deletionPolicy before the finalizer is removed.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
@@ -60,7 +103,7 @@ func (r *NamespaceconfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	log := util.Logs
 	log.Info("Starting namespace config reconcilliation")
 
-	o := &namespaceconfigv1.Namespaceconfig{}
+	o := &namespaceconfigv2.Namespaceconfig{}
 	err := r.Get(ctx, req.NamespacedName, o)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -71,20 +114,40 @@ func (r *NamespaceconfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		}
 		return ctrl.Result{}, err
 	}
+	original := o.Status.DeepCopy()
 	namespaceName := o.Spec.Abbreviation + "-" + o.Spec.Environment
-	finalizerName := "namespaceconfig.myoperator.io/finalizer"
+	if o.Status.NamespaceName != "" && o.Status.NamespaceName != namespaceName {
+		// Abbreviation and Environment are immutable. Objects stored before that was
+		// enforced keep managing the namespace they created instead of orphaning it.
+		log.Error("Namespaceconfig ", o.GetName(), " resolves to namespace ", namespaceName,
+			" but manages namespace ", o.Status.NamespaceName, ". Ignoring the name change")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(o, corev1.EventTypeWarning, "NameChangeIgnored",
+				"Abbreviation and Environment are immutable, keeping namespace %s instead of %s", o.Status.NamespaceName, namespaceName)
+		}
+		namespaceName = o.Status.NamespaceName
+	}
 	namespace := &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name: namespaceName,
 			Annotations: map[string]string{
-				"managed-by": "namespaceconfig.myoperator.io",
+				managedByAnnotation:       managedByValue,
+				namespaceconfigAnnotation: o.GetName(),
+				claimAnnotation:           string(o.GetUID()),
 			},
 			Labels: map[string]string{
-				"owner": o.Spec.NamespaceOwner,
+				"owner": ownerLabel(o),
 				"env":   o.Spec.Environment,
 			},
 		},
 	}
+	r.setPodSecurityLabels(o, namespace.Labels)
+	addCustom(namespace.Labels, o.Spec.NamespaceLabels)
+	addCustom(namespace.Annotations, o.Spec.NamespaceAnnotations)
+	if owners := ownersJSON(o); owners != "" {
+		namespace.Annotations[ownersAnnotation] = owners
+	}
 	// check if the object is being deleted
 	if o.ObjectMeta.DeletionTimestamp.IsZero() {
 		log.Debug("DeletionTimestamp is zero for Namespaceconfig ", o.GetName())
@@ -97,250 +160,90 @@ func (r *NamespaceconfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 				log.Info("Finalizer added to Namespaceconfig ", o.GetName())
 			}
 		}
-		// Attempt to create the namespace
-		err = r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
-		if err != nil {
-			log.Info("Creating Namespace ", namespaceName)
-			if err = r.Create(ctx, namespace); err != nil {
-				return ctrl.Result{}, err
-			} else {
-				log.Info("Namespace ", namespaceName, " created")
-				o.Status.NamespaceName = namespaceName
-				o.Status.Status = "RUNNING"
-				o.Status.LastUpdate = metav1.Now().String()
-				if err := r.Status().Update(ctx, o); err != nil {
-					return ctrl.Result{}, err
-				}
-				log.Info("Namespaceconfig ", o.GetName(), " status updated")
-				nsLimits := r.nsLimits(o, namespaceName)
-				if err := r.Create(ctx, nsLimits); err != nil {
-					log.Error("Failed to create LimitRangefor namespace", namespaceName, ". Error: ", err)
-					return ctrl.Result{}, err
-				} else {
-					log.Info("LimitRange created")
-				}
-				nsQuota := r.nsQuota(o, namespaceName)
-				if err := r.Create(ctx, nsQuota); err != nil {
-					log.Error("Failed to create ResourceQuota for namespace ", namespaceName, ". Error: ", err)
-					return ctrl.Result{}, err
-				} else {
-					log.Info("ResourceQuota created")
-				}
-			}
-		}
+		return r.provision(ctx, &provisioning{
+			nc:          o,
+			original:    original,
+			namespace:   namespace,
+			provisioned: o.Status.NamespaceName == namespaceName,
+		})
 	} else {
 		log.Info("DeletionTimestamp is not zero for Namespaceconfig ", o.GetName())
-		if controllerutil.ContainsFinalizer(o, finalizerName) {
-			log.Info("Finalizer found, removing finalizer ", finalizerName, " from Namespaceconfig ", o.GetName())
-			// Remove the finalizer from the Namespaceconfig object once the cleanup succeeded
-			// This will free up Namespaceconfig resource to be deleted
-			controllerutil.RemoveFinalizer(o, finalizerName)
-			if err := r.Update(ctx, o); err != nil {
-				log.Error("Unable to remove finalizer and update Namespaceconfig ", err)
-				return ctrl.Result{}, err
-			}
-			log.Info("Finalizer removed from Namespaceconfig ", o.GetName())
-		}
-		// Attempt to delete the namespace
-		err = r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				log.Info("Namespace ", namespaceName, " not found")
-			} else {
-				log.Error("Failed to get Namespace ", namespaceName, " ", err)
-				return ctrl.Result{}, err
-			}
-		} else {
-			log.Info("Deleting Namespace ", namespaceName)
-			if err = r.Delete(ctx, namespace); err != nil {
-				log.Error("Failed to delete Namespace ", namespaceName, " ", err)
-				return ctrl.Result{}, err
-			} else {
-				log.Info("Namespace ", namespaceName, " deleted")
-			}
+		return r.finalize(ctx, o, original, namespaceName)
+	}
+}
+
+// ownerLabel is the value of the owner label of the namespace of nc, the
+// first of its owners
+func ownerLabel(nc *namespaceconfigv2.Namespaceconfig) string {
+	if len(nc.Spec.Owners) == 0 {
+		return ""
+	}
+	return util.LabelValue(nc.Spec.Owners[0].Name)
+}
+
+// addCustom adds the custom labels or annotations of a Namespaceconfig to
+// metadata, leaving out the reserved keys. The namespace is server-side
+// applied, so the keys no longer in custom are removed from it.
+func addCustom(metadata, custom map[string]string) {
+	for key, value := range custom {
+		if !util.ReservedKey(key) {
+			metadata[key] = value
 		}
-		return ctrl.Result{}, nil
 	}
-	return ctrl.Result{}, nil
 }
 
-func (r *NamespaceconfigReconciler) nsLimits(nc *namespaceconfigv1.Namespaceconfig, namespaceName string) *corev1.LimitRange {
-	log := util.Logs
-	var (
-		namespaceLimits                              corev1.LimitRangeSpec
-		limitTypePodMaxCPU                           string
-		limitTypePodMaxMemory                        string
-		maxStorage                                   string
-		maxLimitRequestRatioCPU                      string
-		maxLimitRequestRatioMemory                   string
-		limitTypeContainerMaxCPU                     string
-		limitTypeContainerMaxMemory                  string
-		limitTypeContainerDefaultCPU                 string
-		limitTypeContainerDefaultMemory              string
-		limitTypeContainerDefaultRequestCPU          string
-		limitTypeContainerDefaultRequestMemory       string
-		limitTypeContainerMaxLimitRequestRatioCPU    string
-		limitTypeContainerMaxLimitRequestRatioMemory string
-	)
+// removeCustom removes the custom labels or annotations set by addCustom
+func removeCustom(metadata, custom map[string]string) {
+	for key := range custom {
+		if !util.ReservedKey(key) {
+			delete(metadata, key)
+		}
+	}
+}
 
-	if nc.Spec.NamespaceSize == "L" {
-		limitTypePodMaxCPU = "4"
-		limitTypePodMaxMemory = "4Gi"
-		maxStorage = "500Gi"
-		maxLimitRequestRatioCPU = "5"
-		maxLimitRequestRatioMemory = "10Gi"
-		limitTypeContainerMaxCPU = "4"
-		limitTypeContainerMaxMemory = "4Gi"
-		limitTypeContainerDefaultCPU = "0.5"
-		limitTypeContainerDefaultMemory = "512Mi"
-		limitTypeContainerDefaultRequestCPU = "0.1"
-		limitTypeContainerDefaultRequestMemory = "256Mi"
-		limitTypeContainerMaxLimitRequestRatioCPU = "5"
-		limitTypeContainerMaxLimitRequestRatioMemory = "5Gi"
-	} else if nc.Spec.NamespaceSize == "M" {
-		limitTypePodMaxCPU = "2"
-		limitTypePodMaxMemory = "2Gi"
-		maxStorage = "200Gi"
-		maxLimitRequestRatioCPU = "5"
-		maxLimitRequestRatioMemory = "5Gi"
-		limitTypeContainerMaxCPU = "2"
-		limitTypeContainerMaxMemory = "2Gi"
-		limitTypeContainerDefaultCPU = "0.5"
-		limitTypeContainerDefaultMemory = "512Mi"
-		limitTypeContainerDefaultRequestCPU = "0.1"
-		limitTypeContainerDefaultRequestMemory = "256Mi"
-		limitTypeContainerMaxLimitRequestRatioCPU = "5"
-		limitTypeContainerMaxLimitRequestRatioMemory = "5Gi"
-	} else if nc.Spec.NamespaceSize == "S" {
-		limitTypePodMaxCPU = "1"
-		limitTypePodMaxMemory = "1Gi"
-		maxStorage = "100Gi"
-		maxLimitRequestRatioCPU = "5"
-		maxLimitRequestRatioMemory = "2Gi"
-		limitTypeContainerMaxCPU = "1"
-		limitTypeContainerMaxMemory = "1Gi"
-		limitTypeContainerDefaultCPU = "0.5"
-		limitTypeContainerDefaultMemory = "512Mi"
-		limitTypeContainerDefaultRequestCPU = "0.1"
-		limitTypeContainerDefaultRequestMemory = "256Mi"
-		limitTypeContainerMaxLimitRequestRatioCPU = "5"
-		limitTypeContainerMaxLimitRequestRatioMemory = "5Gi"
+// ownersJSON is the value of the owners annotation of the namespace of nc: the
+// owners as JSON, keeping the names and emails a label cannot hold
+func ownersJSON(nc *namespaceconfigv2.Namespaceconfig) string {
+	if len(nc.Spec.Owners) == 0 {
+		return ""
 	}
-	namespaceLimits = corev1.LimitRangeSpec{
-		Limits: []corev1.LimitRangeItem{
-			{
-				Type: corev1.LimitTypePod,
-				Max: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(limitTypePodMaxCPU),
-					corev1.ResourceMemory: resource.MustParse(limitTypePodMaxMemory),
-				},
-				MaxLimitRequestRatio: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(maxLimitRequestRatioCPU),
-					corev1.ResourceMemory: resource.MustParse(maxLimitRequestRatioMemory),
-				},
-			},
-			{
-				Type: corev1.LimitTypeContainer,
-				Max: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(limitTypeContainerMaxCPU),
-					corev1.ResourceMemory: resource.MustParse(limitTypeContainerMaxMemory),
-				},
-				Default: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(limitTypeContainerDefaultCPU),
-					corev1.ResourceMemory: resource.MustParse(limitTypeContainerDefaultMemory)},
-				DefaultRequest: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(limitTypeContainerDefaultRequestCPU),
-					corev1.ResourceMemory: resource.MustParse(limitTypeContainerDefaultRequestMemory),
-				},
-				MaxLimitRequestRatio: map[corev1.ResourceName]resource.Quantity{
-					corev1.ResourceCPU:    resource.MustParse(limitTypeContainerMaxLimitRequestRatioCPU),
-					corev1.ResourceMemory: resource.MustParse(limitTypeContainerMaxLimitRequestRatioMemory),
-				},
-			},
-			{
-				Type: corev1.LimitTypePersistentVolumeClaim,
-				Max:  map[corev1.ResourceName]resource.Quantity{corev1.ResourceStorage: resource.MustParse(maxStorage)},
-			},
-		},
+	owners, err := json.Marshal(nc.Spec.Owners)
+	if err != nil {
+		return ""
 	}
-	// if nc.Spec.NamespaceSize == "S" {
-	// 	namespaceLimits = corev1.LimitRangeSpec{
-	// 		Limits: []corev1.LimitRangeItem{
-	// 			{
-	// 				Type: corev1.LimitTypePod,
-	// 				Max: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("4"),
-	// 					corev1.ResourceMemory: resource.MustParse("4Gi"),
-	// 				},
-	// 				MaxLimitRequestRatio: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("5"),
-	// 					corev1.ResourceMemory: resource.MustParse("10Gi"),
-	// 				},
-	// 			},
-	// 			{
-	// 				Type: corev1.LimitTypeContainer,
-	// 				Max: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("4"),
-	// 					corev1.ResourceMemory: resource.MustParse("4Gi"),
-	// 				},
-	// 				Default: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("0.5"),
-	// 					corev1.ResourceMemory: resource.MustParse("512Mi")},
-	// 				DefaultRequest: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("0.1"),
-	// 					corev1.ResourceMemory: resource.MustParse("256Mi"),
-	// 				},
-	// 				MaxLimitRequestRatio: map[corev1.ResourceName]resource.Quantity{
-	// 					corev1.ResourceCPU:    resource.MustParse("5"),
-	// 					corev1.ResourceMemory: resource.MustParse("5Gi"),
-	// 				},
-	// 			},
-	// 			{
-	// 				Type: corev1.LimitTypePersistentVolumeClaim,
-	// 				Max:  map[corev1.ResourceName]resource.Quantity{corev1.ResourceStorage: resource.MustParse("500Gi")},
-	// 			},
-	// 		},
-	// 	}
-	// }
+	return string(owners)
+}
+
+// nsLimits builds the LimitRange of the namespace from the effective limits of size
+func (r *NamespaceconfigReconciler) nsLimits(nc *namespaceconfigv2.Namespaceconfig, size string, spec corev1.LimitRangeSpec, namespaceName string) (*corev1.LimitRange, error) {
 	limits := &corev1.LimitRange{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      namespaceName + "-limits-" + strings.ToLower(nc.Spec.NamespaceSize),
+			Name:      childName(namespaceName, size),
 			Namespace: namespaceName,
 		},
-		Spec: namespaceLimits,
+		Spec: *spec.DeepCopy(),
 	}
 	if err := ctrl.SetControllerReference(nc, limits, r.Scheme); err != nil {
-		log.Error("Unable to set ownerReference for namespace ", namespaceName, ". Error: ", err)
+		return nil, err
 	}
-	return limits
+	return limits, nil
 }
 
-func (r *NamespaceconfigReconciler) nsQuota(nc *namespaceconfigv1.Namespaceconfig, namespaceName string) *corev1.ResourceQuota {
-	log := util.Logs
-	namespaceQuota := &corev1.ResourceQuotaSpec{}
-	if nc.Spec.NamespaceSize == "S" {
-		namespaceQuota = &corev1.ResourceQuotaSpec{
-			Hard: corev1.ResourceList{
-				corev1.ResourceName("cpu"):                    resource.MustParse("8"),
-				corev1.ResourceName("memory"):                 resource.MustParse("8Gi"),
-				corev1.ResourceName("persistentvolumeclaims"): resource.MustParse("200"),
-				corev1.ResourceName("pods"):                   resource.MustParse("200"),
-				corev1.ResourceName("replicationcontrollers"): resource.MustParse("200"),
-				corev1.ResourceName("services"):               resource.MustParse("200"),
-			},
-		}
-	}
+// nsQuota builds the ResourceQuota of the namespace from the effective quota of size
+func (r *NamespaceconfigReconciler) nsQuota(nc *namespaceconfigv2.Namespaceconfig, size string, spec corev1.ResourceQuotaSpec, namespaceName string) (*corev1.ResourceQuota, error) {
 	quota := &corev1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ResourceQuota"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      namespaceName + "-limits-" + strings.ToLower(nc.Spec.NamespaceSize),
+			Name:      childName(namespaceName, size),
 			Namespace: namespaceName,
 		},
-		Spec: *namespaceQuota,
+		Spec: *spec.DeepCopy(),
 	}
 	if err := ctrl.SetControllerReference(nc, quota, r.Scheme); err != nil {
-		log.Error("Unable to set ownerReference for namespace ", namespaceName, ". Error: ", err)
+		return nil, err
 	}
-	return quota
+	return quota, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -351,10 +254,17 @@ func (r *NamespaceconfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return true
 		},
 		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
-			oldNS := updateEvent.ObjectOld.(*corev1.Namespace)
-			newNS := updateEvent.ObjectNew.(*corev1.Namespace)
+			oldNS, okOld := updateEvent.ObjectOld.(*corev1.Namespace)
+			newNS, okNew := updateEvent.ObjectNew.(*corev1.Namespace)
+			if !okOld || !okNew {
+				return true
+			}
 			same := reflect.DeepEqual(oldNS.ObjectMeta.Labels, newNS.ObjectMeta.Labels)
+			same = same && reflect.DeepEqual(oldNS.ObjectMeta.Annotations, newNS.ObjectMeta.Annotations)
 			same = same && oldNS.ObjectMeta.Name == newNS.ObjectMeta.Name
+			// follow the termination of the namespace
+			same = same && oldNS.ObjectMeta.DeletionTimestamp.Equal(newNS.ObjectMeta.DeletionTimestamp)
+			same = same && reflect.DeepEqual(oldNS.Status, newNS.Status)
 			return !same
 		},
 		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
@@ -365,8 +275,73 @@ func (r *NamespaceconfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&namespaceconfigv1.Namespaceconfig{}).
-		Owns(&corev1.Namespace{}, predicateNamespace).
-		Complete(r)
+	// LimitRanges and ResourceQuotas carry no metadata.generation, and the
+	// status of a ResourceQuota changes with every pod of the namespace. Only
+	// changes to their spec or metadata can be drift.
+	predicateSpec := builder.WithPredicates(predicate.Or(
+		predicate.LabelChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+		predicate.Funcs{
+			UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+				switch oldObj := updateEvent.ObjectOld.(type) {
+				case *corev1.LimitRange:
+					newObj, ok := updateEvent.ObjectNew.(*corev1.LimitRange)
+					return !ok || !reflect.DeepEqual(oldObj.Spec, newObj.Spec) ||
+						!reflect.DeepEqual(oldObj.OwnerReferences, newObj.OwnerReferences)
+				case *corev1.ResourceQuota:
+					newObj, ok := updateEvent.ObjectNew.(*corev1.ResourceQuota)
+					return !ok || !reflect.DeepEqual(oldObj.Spec, newObj.Spec) ||
+						!reflect.DeepEqual(oldObj.OwnerReferences, newObj.OwnerReferences)
+				}
+				return true
+			},
+		},
+	))
+
+	// a bug in Reconcile fails the reconcile of one Namespaceconfig instead of the manager
+	recoverPanic := true
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RecoverPanic: &recoverPanic}).
+		For(&namespaceconfigv2.Namespaceconfig{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.namespaceToNamespaceconfig), predicateNamespace).
+		Owns(&corev1.LimitRange{}, predicateSpec).
+		Owns(&corev1.ResourceQuota{}, predicateSpec).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(&namespaceconfigv1.NamespaceSizeProfile{}, handler.EnqueueRequestsFromMapFunc(r.profileToNamespaceconfigs)).
+		Watches(&namespaceconfigv2.NamespaceTemplate{}, handler.EnqueueRequestsFromMapFunc(r.templateToNamespaceconfigs))
+	if r.ReplicationNamespace != "" {
+		// the cache of the manager only holds the Secrets and ConfigMaps of the replication namespace
+		sources := builder.WithPredicates(r.replicationSources())
+		b = b.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.sourceToNamespaceconfigs), sources).
+			Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.sourceToNamespaceconfigs), sources)
+	}
+	return b.Complete(r)
+}
+
+// namespaceToNamespaceconfig maps a managed namespace to its Namespaceconfig.
+// Namespaces are cluster-scoped like Namespaceconfigs but carry no owner
+// reference, so that deleting a Namespaceconfig does not garbage collect them.
+func (r *NamespaceconfigReconciler) namespaceToNamespaceconfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetAnnotations()[namespaceconfigAnnotation]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+}
+
+// profileToNamespaceconfigs maps a NamespaceSizeProfile to every Namespaceconfig using it
+func (r *NamespaceconfigReconciler) profileToNamespaceconfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &namespaceconfigv2.NamespaceconfigList{}
+	if err := r.List(ctx, list); err != nil {
+		util.Logs.Error("Failed to list Namespaceconfigs for NamespaceSizeProfile ", obj.GetName(), ". Error: ", err)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, nc := range list.Items {
+		if strings.ToLower(nc.Spec.Size) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: nc.GetName()}})
+		}
+	}
+	return requests
 }
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -15,9 +18,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
 )
 
-func ReadNamespaceConfigFromFile(filename string) (*namespaceconfigv1.Namespaceconfig, error) {
+func ReadNamespaceConfigFromFile(filename string) (*namespaceconfigv2.Namespaceconfig, error) {
 	testfile := path.Join("..", "..", "config", "samples", filename)
 	content, err := ioutil.ReadFile(filepath.Clean(testfile))
 	if err != nil {
@@ -28,16 +32,34 @@ func ReadNamespaceConfigFromFile(filename string) (*namespaceconfigv1.Namespacec
 	if err != nil {
 		return nil, err
 	}
-	return obj.(*namespaceconfigv1.Namespaceconfig), nil
+	return obj.(*namespaceconfigv2.Namespaceconfig), nil
 }
 
-var _ = Describe("Namespace controller", func() {
+func ReadNamespaceSizeProfileFromFile(filename string) (*namespaceconfigv1.NamespaceSizeProfile, error) {
+	testfile := path.Join("..", "..", "config", "profiles", filename)
+	content, err := ioutil.ReadFile(filepath.Clean(testfile))
+	if err != nil {
+		return nil, err
+	}
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	obj, _, err := decode(content, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*namespaceconfigv1.NamespaceSizeProfile), nil
+}
+
+var _ = Describe("Namespace controller", Ordered, func() {
+	BeforeAll(useTestCluster)
 
 	Context("NamespaceConfig with given size", func() {
 		It("Should create namespace successfully", func() {
 			By("Creating a new Namespace")
 			ctx := context.Background()
-			nc, err1 := ReadNamespaceConfigFromFile("namespaceconfig_v1_namespaceconfig.yaml")
+			profile, err := ReadNamespaceSizeProfileFromFile("namespacesizeprofile_s.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+			nc, err1 := ReadNamespaceConfigFromFile("namespaceconfig_v2_namespaceconfig.yaml")
 			Expect(err1).ToNot(HaveOccurred())
 			Expect(k8sClient.Create(ctx, nc)).Should(Succeed())
 			// Verify Namespace
@@ -49,13 +71,60 @@ var _ = Describe("Namespace controller", func() {
 			}, time.Second*30, time.Second*3).Should(BeTrue())
 			Expect(ns.ObjectMeta.Labels).To(HaveKeyWithValue("env", "dev"))
 			Expect(ns.ObjectMeta.Labels).To(HaveKeyWithValue("owner", "mukund"))
+			Expect(ns.ObjectMeta.Annotations).To(HaveKey(namespaceconfigv2.OwnersAnnotation))
+			Expect(ns.ObjectMeta.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
 			Expect(ns.Status.Phase).To(Equal(v1.NamespaceActive))
+			// Verify status
+			Eventually(func() bool {
+				_ = k8sClient.Get(ctx, types.NamespacedName{Name: nc.GetName()}, nc)
+				return meta.IsStatusConditionTrue(nc.Status.Conditions, namespaceconfigv2.ConditionReady)
+			}, time.Second*30, time.Second*3).Should(BeTrue())
+			Expect(nc.Status.ObservedGeneration).To(Equal(nc.GetGeneration()))
+			Expect(meta.IsStatusConditionTrue(nc.Status.Conditions, namespaceconfigv2.ConditionLimitsApplied)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(nc.Status.Conditions, namespaceconfigv2.ConditionQuotaApplied)).To(BeTrue())
+			Expect(nc.Status.Steps).To(HaveLen(9))
+			for _, step := range nc.Status.Steps {
+				Expect(step.State).To(Equal(namespaceconfigv2.StepSucceeded), step.Name)
+			}
+			// Verify owner access
+			binding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "namespaceconfig-owner-admin", Namespace: nsname}, binding)).Should(Succeed())
+			Expect(binding.RoleRef.Name).To(Equal("admin"))
+			Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "mukund")))
+			// Verify network isolation
+			Expect(meta.IsStatusConditionTrue(nc.Status.Conditions, namespaceconfigv2.ConditionNetworkIsolated)).To(BeTrue())
+			policy := &networkingv1.NetworkPolicy{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "namespaceconfig-default-deny", Namespace: nsname}, policy)).Should(Succeed())
+			Expect(policy.Spec.PolicyTypes).To(ContainElement(networkingv1.PolicyTypeIngress))
+			// Verify drift correction
+			By("Deleting the managed ResourceQuota")
+			quota := &v1.ResourceQuota{}
+			quotaKey := types.NamespacedName{Name: nsname + "-limits-s", Namespace: nsname}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, quotaKey, quota)
+			}, time.Second*30, time.Second*3).Should(Succeed())
+			uid := quota.ObjectMeta.UID
+			Expect(k8sClient.Delete(ctx, quota)).Should(Succeed())
+			Eventually(func() bool {
+				restored := &v1.ResourceQuota{}
+				err := k8sClient.Get(ctx, quotaKey, restored)
+				return err == nil && restored.ObjectMeta.UID != uid
+			}, time.Second*30, time.Second*3).Should(BeTrue())
 			//Delete namespace
 			By("Deleting an existing namespacedef")
 			Eventually(func() bool {
 				errdel := k8sClient.Delete(ctx, nc)
 				return errdel == nil
 			}, time.Second*30, time.Second*3).Should(BeTrue())
+			// envtest runs no namespace controller, the namespace never finishes terminating
+			By("Holding the Namespaceconfig while its namespace terminates")
+			Eventually(func() bool {
+				found := &namespaceconfigv2.Namespaceconfig{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: nc.GetName()}, found); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(found.Status.Conditions, namespaceconfigv2.ConditionDeleting)
+			}, time.Second*30, time.Second*3).Should(BeTrue())
 		})
 	})
 })
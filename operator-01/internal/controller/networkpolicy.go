@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+const (
+	// networkPolicyPrefix starts the names of the NetworkPolicies of a namespace
+	networkPolicyPrefix = "namespaceconfig-"
+	// namespaceNameLabel is set by the API server on every namespace to its name
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	// dnsNamespace and dnsLabel select the cluster DNS pods
+	dnsNamespace = "kube-system"
+	dnsLabel     = "k8s-app"
+	dnsApp       = "kube-dns"
+)
+
+// nsNetworkPolicies builds the NetworkPolicies of bundle for the namespace.
+// Every bundle but none denies ingress and allows it from the namespace itself
+// and from the ingress controller. strict denies egress as well, except within
+// the namespace and to the cluster DNS.
+func (r *NamespaceconfigReconciler) nsNetworkPolicies(nc *namespaceconfigv2.Namespaceconfig, bundle, namespaceName string) ([]*networkingv1.NetworkPolicy, error) {
+	if bundle == config.NetworkPoliciesNone {
+		return nil, nil
+	}
+	strict := bundle == config.NetworkPoliciesStrict
+	ingress := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	both := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+
+	deny := networkPolicy(namespaceName, "default-deny", ingress)
+	same := networkPolicy(namespaceName, "allow-same-namespace", ingress)
+	same.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: sameNamespace}}
+	if strict {
+		deny.Spec.PolicyTypes = both
+		same.Spec.PolicyTypes = both
+		same.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{To: sameNamespace}}
+	}
+	policies := []*networkingv1.NetworkPolicy{deny, same}
+
+	if strict {
+		udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+		port := intstr.FromInt32(53)
+		dns := networkPolicy(namespaceName, "allow-dns", []networkingv1.PolicyType{networkingv1.PolicyTypeEgress})
+		dns.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{
+			To: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: dnsNamespace}},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{dnsLabel: dnsApp}},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &port}, {Protocol: &tcp, Port: &port}},
+		}}
+		policies = append(policies, dns)
+	}
+
+	if r.IngressNamespace != "" {
+		ingressController := networkPolicy(namespaceName, "allow-ingress-controller", ingress)
+		ingressController.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: r.IngressNamespace}},
+			}},
+		}}
+		policies = append(policies, ingressController)
+	}
+
+	for _, policy := range policies {
+		if err := ctrl.SetControllerReference(nc, policy, r.Scheme); err != nil {
+			return nil, err
+		}
+	}
+	return policies, nil
+}
+
+// networkPolicy builds a NetworkPolicy selecting every pod of the namespace
+func networkPolicy(namespaceName, name string, policyTypes []networkingv1.PolicyType) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyPrefix + name,
+			Namespace: namespaceName,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: policyTypes,
+		},
+	}
+}
+
+// applyNetworkPolicies applies the NetworkPolicies of the environment, and
+// deletes the ones no longer in its bundle or all of them when the
+// Namespaceconfig opts out
+func (r *NamespaceconfigReconciler) applyNetworkPolicies(ctx context.Context, p *provisioning) *stepError {
+	conditions := []string{namespaceconfigv2.ConditionNetworkIsolated}
+	bundle := r.NetworkPolicies.For(p.nc.Spec.Environment)
+	if p.nc.Spec.DisableNetworkPolicies {
+		bundle = config.NetworkPoliciesNone
+	}
+	policies, err := r.nsNetworkPolicies(p.nc, bundle, p.namespace.GetName())
+	if err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	desired := sets.New[string]()
+	for _, policy := range policies {
+		desired.Insert(policy.GetName())
+		if err := r.applyChild(ctx, p.nc, policy, p.provisioned); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+
+	live := &networkingv1.NetworkPolicyList{}
+	if err := r.List(ctx, live, client.InNamespace(p.namespace.GetName())); err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	for i := range live.Items {
+		policy := &live.Items[i]
+		if !metav1.IsControlledBy(policy, p.nc) || desired.Has(policy.GetName()) {
+			continue
+		}
+		util.Logs.Info("Deleting NetworkPolicy ", policy.GetName(), " no longer in the bundle of Namespaceconfig ", p.nc.GetName())
+		if err := r.Delete(ctx, policy); err != nil && !errors.IsNotFound(err) {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+
+	switch {
+	case p.nc.Spec.DisableNetworkPolicies:
+		setCondition(p.nc, namespaceconfigv2.ConditionNetworkIsolated, metav1.ConditionFalse, "Disabled",
+			"NetworkPolicies are disabled by spec.disableNetworkPolicies")
+	case bundle == config.NetworkPoliciesNone:
+		setCondition(p.nc, namespaceconfigv2.ConditionNetworkIsolated, metav1.ConditionFalse, "NoBundle",
+			"No NetworkPolicies are set for environment "+p.nc.Spec.Environment)
+	default:
+		setCondition(p.nc, namespaceconfigv2.ConditionNetworkIsolated, metav1.ConditionTrue, "Applied",
+			"NetworkPolicies of the "+bundle+" bundle are applied")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// podSecurityLabelPrefix starts the Pod Security Admission labels of a namespace
+const podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+
+// podSecurityModes are the Pod Security Admission modes set on a namespace
+var podSecurityModes = []string{"enforce", "audit", "warn"}
+
+// podSecurityLevel is the Pod Security Standard of the namespace of nc: the
+// level of its environment, or its podSecurity when it is annotated with the
+// reason of the exemption. An override is reported in the PodSecurityOverridden
+// condition, and an ignored one with an event when it is first seen.
+func (r *NamespaceconfigReconciler) podSecurityLevel(nc *namespaceconfigv2.Namespaceconfig) namespaceconfigv2.PodSecurityLevel {
+	if nc.Spec.PodSecurity == "" {
+		meta.RemoveStatusCondition(&nc.Status.Conditions, namespaceconfigv2.ConditionPodSecurityOverridden)
+		return r.PodSecurity.For(nc.Spec.Environment)
+	}
+	exemption := nc.GetAnnotations()[namespaceconfigv2.PodSecurityExemptionAnnotation]
+	if exemption == "" {
+		message := fmt.Sprintf("podSecurity %s is ignored without the annotation %s",
+			nc.Spec.PodSecurity, namespaceconfigv2.PodSecurityExemptionAnnotation)
+		if !meta.IsStatusConditionFalse(nc.Status.Conditions, namespaceconfigv2.ConditionPodSecurityOverridden) {
+			util.Logs.Info("Namespaceconfig ", nc.GetName(), " overrides ", message)
+			if r.Recorder != nil {
+				r.Recorder.Event(nc, corev1.EventTypeWarning, "ExemptionMissing", message)
+			}
+		}
+		setCondition(nc, namespaceconfigv2.ConditionPodSecurityOverridden, metav1.ConditionFalse, "ExemptionMissing", message)
+		return r.PodSecurity.For(nc.Spec.Environment)
+	}
+	setCondition(nc, namespaceconfigv2.ConditionPodSecurityOverridden, metav1.ConditionTrue, "Exempted",
+		fmt.Sprintf("podSecurity %s applies: %s", nc.Spec.PodSecurity, exemption))
+	return nc.Spec.PodSecurity
+}
+
+// setPodSecurityLabels sets the Pod Security Admission labels of every mode,
+// pinned to the version of the reconciler, on the labels of a namespace
+func (r *NamespaceconfigReconciler) setPodSecurityLabels(nc *namespaceconfigv2.Namespaceconfig, labels map[string]string) {
+	level := string(r.podSecurityLevel(nc))
+	version := r.PodSecurityVersion
+	if version == "" {
+		version = "latest"
+	}
+	for _, mode := range podSecurityModes {
+		labels[podSecurityLabelPrefix+mode] = level
+		labels[podSecurityLabelPrefix+mode+"-version"] = version
+	}
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+)
+
+var _ = Describe("Pod Security", func() {
+	It("reports an override without exemption once and applies it with one", func() {
+		nc := testNamespaceconfig("psa", "psa", "prod")
+		nc.Spec.PodSecurity = namespaceconfigv2.PodSecurityPrivileged
+		recorder := record.NewFakeRecorder(10)
+		r := fakeReconciler()
+		r.Recorder = recorder
+		r.PodSecurity = config.PodSecurityLevels{"prod": namespaceconfigv2.PodSecurityRestricted}
+
+		labels := map[string]string{}
+		r.setPodSecurityLabels(nc, labels)
+		r.setPodSecurityLabels(nc, labels)
+		Expect(labels).To(HaveKeyWithValue(podSecurityLabelPrefix+"enforce", "restricted"))
+		Expect(recorder.Events).To(HaveLen(1))
+		overridden := meta.FindStatusCondition(nc.Status.Conditions, namespaceconfigv2.ConditionPodSecurityOverridden)
+		Expect(overridden).NotTo(BeNil())
+		Expect(overridden.Status).To(Equal(metav1.ConditionFalse))
+		Expect(overridden.Reason).To(Equal("ExemptionMissing"))
+
+		By("Annotating the reason of the exemption")
+		nc.SetAnnotations(map[string]string{namespaceconfigv2.PodSecurityExemptionAnnotation: "runs the CNI"})
+		r.setPodSecurityLabels(nc, labels)
+		Expect(labels).To(HaveKeyWithValue(podSecurityLabelPrefix+"enforce", "privileged"))
+		Expect(meta.IsStatusConditionTrue(nc.Status.Conditions, namespaceconfigv2.ConditionPodSecurityOverridden)).To(BeTrue())
+
+		By("Dropping the override")
+		nc.Spec.PodSecurity = ""
+		r.setPodSecurityLabels(nc, labels)
+		Expect(labels).To(HaveKeyWithValue(podSecurityLabelPrefix+"enforce", "restricted"))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, namespaceconfigv2.ConditionPodSecurityOverridden)).To(BeNil())
+	})
+})
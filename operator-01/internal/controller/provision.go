@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/sizing"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// provisioning is the state shared by the provisioning steps of one reconcile
+type provisioning struct {
+	nc        *namespaceconfigv2.Namespaceconfig
+	original  *namespaceconfigv2.NamespaceconfigStatus
+	namespace *corev1.Namespace
+	size      string
+	limits    corev1.LimitRangeSpec
+	quota     corev1.ResourceQuotaSpec
+	// provisioned tells whether the namespace was applied by an earlier reconcile
+	provisioned bool
+	resizing    bool
+}
+
+// stepError is the failure of a provisioning step, reported with reason on conditions
+type stepError struct {
+	reason     string
+	err        error
+	conditions []string
+	// blocked is set when retrying right away cannot help
+	blocked bool
+}
+
+// provisioningStep is one idempotent part of provisioning a Namespaceconfig
+type provisioningStep struct {
+	name string
+	run  func(ctx context.Context, p *provisioning) *stepError
+}
+
+// provisioningSteps are run in order on every reconcile, so that a step that
+// failed is retried and objects changed outside of the operator are converged.
+// New children of the namespace are added here.
+func (r *NamespaceconfigReconciler) provisioningSteps() []provisioningStep {
+	return []provisioningStep{
+		{namespaceconfigv2.StepSize, r.resolveSize},
+		{namespaceconfigv2.StepNamespace, r.applyNamespace},
+		{namespaceconfigv2.StepLimitRange, r.applyLimits},
+		{namespaceconfigv2.StepResourceQuota, r.applyQuota},
+		{namespaceconfigv2.StepRoleBindings, r.applyAccess},
+		{namespaceconfigv2.StepNetworkPolicies, r.applyNetworkPolicies},
+		{namespaceconfigv2.StepTemplates, r.applyTemplates},
+		{namespaceconfigv2.StepReplication, r.replicate},
+		{namespaceconfigv2.StepCleanup, r.cleanup},
+	}
+}
+
+// provision runs the provisioning steps and records the outcome of each of
+// them in the status. The first failing step stops the run, the following
+// steps stay pending until the next reconcile.
+func (r *NamespaceconfigReconciler) provision(ctx context.Context, p *provisioning) (ctrl.Result, error) {
+	log := util.Logs
+	o := p.nc
+	steps := r.provisioningSteps()
+	for i, step := range steps {
+		stepErr := step.run(ctx, p)
+		if stepErr == nil {
+			setStep(o, step.name, namespaceconfigv2.StepSucceeded, "")
+			continue
+		}
+		log.Error("Step ", step.name, " of Namespaceconfig ", o.GetName(), " failed. Error: ", stepErr.err)
+		setStep(o, step.name, namespaceconfigv2.StepFailed, stepErr.err.Error())
+		for _, next := range steps[i+1:] {
+			setStep(o, next.name, namespaceconfigv2.StepPending, "Waiting for step "+step.name)
+		}
+		if stepErr.blocked {
+			return r.blocked(ctx, o, p.original, stepErr.reason, stepErr.err.Error(), stepErr.conditions...)
+		}
+		return r.failed(ctx, o, p.original, stepErr.reason, stepErr.err, stepErr.conditions...)
+	}
+
+	if p.resizing {
+		log.Info("Namespace ", p.namespace.GetName(), " resized from ", o.Status.AppliedSize, " to ", p.size)
+		setCondition(o, namespaceconfigv2.ConditionResized, metav1.ConditionTrue, "Resized",
+			fmt.Sprintf("Resized from %s to %s", o.Status.AppliedSize, p.size))
+	}
+	o.Status.NamespaceName = p.namespace.GetName()
+	o.Status.EffectiveLimits = &p.limits
+	o.Status.EffectiveQuota = &p.quota
+	o.Status.AppliedSize = p.size
+	setCondition(o, namespaceconfigv2.ConditionReady, metav1.ConditionTrue, "Provisioned",
+		fmt.Sprintf("Namespace %s is provisioned with size %s", p.namespace.GetName(), p.size))
+	return ctrl.Result{}, r.updateStatus(ctx, o, p.original)
+}
+
+// resolveSize looks up the size profile before anything is applied, so that a
+// namespace is never created without its LimitRange and ResourceQuota. A
+// resize that would put the current usage above the new quota is refused.
+func (r *NamespaceconfigReconciler) resolveSize(ctx context.Context, p *provisioning) *stepError {
+	o := p.nc
+	sizeConditions := []string{namespaceconfigv2.ConditionLimitsApplied, namespaceconfigv2.ConditionQuotaApplied}
+	size, err := sizing.ProfileName(o)
+	if err != nil {
+		return &stepError{reason: profileReason(err), err: err, conditions: sizeConditions}
+	}
+	p.limits, p.quota, err = r.effectiveSize(ctx, o, size)
+	if err != nil {
+		return &stepError{reason: profileReason(err), err: err, conditions: sizeConditions}
+	}
+	p.size = size
+	p.resizing = o.Status.AppliedSize != "" && o.Status.AppliedSize != size
+	if !p.resizing {
+		// a refused resize is over once spec.size is back to the applied size
+		if meta.IsStatusConditionFalse(o.Status.Conditions, namespaceconfigv2.ConditionResized) {
+			meta.RemoveStatusCondition(&o.Status.Conditions, namespaceconfigv2.ConditionResized)
+		}
+		return nil
+	}
+	exceeded, err := r.usageAboveQuota(ctx, o, p.namespace.GetName(), p.quota)
+	if err != nil {
+		return &stepError{reason: "UsageUnknown", err: err, conditions: []string{namespaceconfigv2.ConditionQuotaApplied}}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("Resize from %s to %s refused, current usage is above the new quota: %s",
+		o.Status.AppliedSize, size, strings.Join(exceeded, ", "))
+	util.Logs.Error(message, " for Namespaceconfig ", o.GetName())
+	if r.Recorder != nil {
+		r.Recorder.Event(o, corev1.EventTypeWarning, "ResizeRefused", message)
+	}
+	setCondition(o, namespaceconfigv2.ConditionResized, metav1.ConditionFalse, "UsageAboveQuota", message)
+	// keep converging the size that is currently applied
+	p.size = o.Status.AppliedSize
+	p.resizing = false
+	p.limits, p.quota, err = r.effectiveSize(ctx, o, p.size)
+	if err != nil {
+		return &stepError{reason: profileReason(err), err: err, conditions: sizeConditions}
+	}
+	return nil
+}
+
+// applyNamespace applies the namespace. It never takes over a namespace created
+// by someone else or claimed by another Namespaceconfig resolving to the same name.
+func (r *NamespaceconfigReconciler) applyNamespace(ctx context.Context, p *provisioning) *stepError {
+	conditions := []string{namespaceconfigv2.ConditionNamespaceProvisioned}
+	live := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(p.namespace), live); err == nil {
+		if !isClaimedBy(live, p.nc) {
+			return &stepError{reason: "NamespaceConflict", err: fmt.Errorf("%s", claimConflict(live)), conditions: conditions, blocked: true}
+		}
+	} else if !errors.IsNotFound(err) {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	if err := r.applyChild(ctx, p.nc, p.namespace, p.provisioned); err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	setCondition(p.nc, namespaceconfigv2.ConditionNamespaceProvisioned, metav1.ConditionTrue, "Applied", "Namespace "+p.namespace.GetName()+" is applied")
+	return nil
+}
+
+// applyLimits applies the LimitRange of the size
+func (r *NamespaceconfigReconciler) applyLimits(ctx context.Context, p *provisioning) *stepError {
+	limits, err := r.nsLimits(p.nc, p.size, p.limits, p.namespace.GetName())
+	if err == nil {
+		err = r.applyChild(ctx, p.nc, limits, p.provisioned && !p.resizing)
+	}
+	if err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: []string{namespaceconfigv2.ConditionLimitsApplied}}
+	}
+	setCondition(p.nc, namespaceconfigv2.ConditionLimitsApplied, metav1.ConditionTrue, "Applied", "LimitRange "+limits.GetName()+" is applied")
+	return nil
+}
+
+// applyQuota applies the ResourceQuota of the size
+func (r *NamespaceconfigReconciler) applyQuota(ctx context.Context, p *provisioning) *stepError {
+	quota, err := r.nsQuota(p.nc, p.size, p.quota, p.namespace.GetName())
+	if err == nil {
+		err = r.applyChild(ctx, p.nc, quota, p.provisioned && !p.resizing)
+	}
+	if err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: []string{namespaceconfigv2.ConditionQuotaApplied}}
+	}
+	setCondition(p.nc, namespaceconfigv2.ConditionQuotaApplied, metav1.ConditionTrue, "Applied", "ResourceQuota "+quota.GetName()+" is applied")
+	return nil
+}
+
+// cleanup removes the LimitRange and ResourceQuota of a previous size
+func (r *NamespaceconfigReconciler) cleanup(ctx context.Context, p *provisioning) *stepError {
+	if err := r.cleanupSizes(ctx, p.nc, p.namespace.GetName(), p.size); err != nil {
+		return &stepError{reason: "CleanupFailed", err: err,
+			conditions: []string{namespaceconfigv2.ConditionLimitsApplied, namespaceconfigv2.ConditionQuotaApplied}}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/replication"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// nsReplicas builds the copies of the Secrets and ConfigMaps of the
+// replication namespace selected for the namespace
+func (r *NamespaceconfigReconciler) nsReplicas(ctx context.Context, p *provisioning) ([]client.Object, error) {
+	environment, namespaceName := p.nc.Spec.Environment, p.namespace.GetName()
+	replicas := []client.Object{}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(r.ReplicationNamespace)); err != nil {
+		return nil, err
+	}
+	for i := range secrets.Items {
+		source := &secrets.Items[i]
+		if !replication.Replicates(source, environment, p.size) {
+			continue
+		}
+		replicas = append(replicas, &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: replication.Meta(source, namespaceName),
+			Type:       source.Type,
+			Data:       source.Data,
+		})
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.List(ctx, configMaps, client.InNamespace(r.ReplicationNamespace)); err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		source := &configMaps.Items[i]
+		if !replication.Replicates(source, environment, p.size) {
+			continue
+		}
+		replicas = append(replicas, &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: replication.Meta(source, namespaceName),
+			Data:       source.Data,
+			BinaryData: source.BinaryData,
+		})
+	}
+	for _, replica := range replicas {
+		if err := ctrl.SetControllerReference(p.nc, replica, r.Scheme); err != nil {
+			return nil, err
+		}
+	}
+	return replicas, nil
+}
+
+// replicate copies the selected Secrets and ConfigMaps of the replication
+// namespace into the namespace and deletes the copies whose source is gone or
+// no longer selects the namespace. Objects of the namespace that are not
+// copies are never overwritten. With replication off, the copies left by an
+// earlier run are deleted once and the namespace is not looked at again.
+func (r *NamespaceconfigReconciler) replicate(ctx context.Context, p *provisioning) *stepError {
+	conditions := []string{namespaceconfigv2.ConditionReplicated}
+	namespaceName := p.namespace.GetName()
+	if r.ReplicationNamespace == "" {
+		if meta.FindStatusCondition(p.nc.Status.Conditions, namespaceconfigv2.ConditionReplicated) == nil {
+			return nil
+		}
+		if err := r.pruneReplicas(ctx, p.nc, namespaceName, map[string]bool{}); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+		meta.RemoveStatusCondition(&p.nc.Status.Conditions, namespaceconfigv2.ConditionReplicated)
+		return nil
+	}
+
+	replicas := []client.Object{}
+	if namespaceName != r.ReplicationNamespace {
+		var err error
+		if replicas, err = r.nsReplicas(ctx, p); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+
+	desired := map[string]bool{}
+	conflicts := []string{}
+	for _, replica := range replicas {
+		kind := replica.GetObjectKind().GroupVersionKind().Kind
+		live, err := r.newLike(replica)
+		if err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+		err = r.Get(ctx, client.ObjectKeyFromObject(replica), live)
+		if err != nil && !errors.IsNotFound(err) {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+		exists := err == nil
+		source := replica.GetAnnotations()[namespaceconfigv2.ReplicatedFromAnnotation]
+		if exists && replication.Conflicts(live, p.nc, source) {
+			conflicts = append(conflicts, kind+" "+replica.GetName())
+			continue
+		}
+		desired[kind+"/"+replica.GetName()] = true
+		if err := r.applyChild(ctx, p.nc, replica, exists); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+
+	if err := r.pruneReplicas(ctx, p.nc, namespaceName, desired); err != nil {
+		return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+
+	if len(conflicts) > 0 {
+		message := fmt.Sprintf("Not replicated over objects of the namespace: %s", strings.Join(conflicts, ", "))
+		util.Logs.Error(message, " for Namespaceconfig ", p.nc.GetName())
+		setCondition(p.nc, namespaceconfigv2.ConditionReplicated, metav1.ConditionFalse, "Conflict", message)
+		return nil
+	}
+	setCondition(p.nc, namespaceconfigv2.ConditionReplicated, metav1.ConditionTrue, "Replicated",
+		fmt.Sprintf("%d objects of namespace %s are replicated", len(desired), r.ReplicationNamespace))
+	return nil
+}
+
+// replicasOf returns the copies controlled by the Namespaceconfig in its
+// namespace, by Kind/name. Only the labelled objects of the namespace are listed.
+func (r *NamespaceconfigReconciler) replicasOf(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName string) (map[string]client.Object, error) {
+	opts := []client.ListOption{
+		client.InNamespace(namespaceName),
+		client.MatchingLabels{namespaceconfigv2.ReplicatedLabel: "true"},
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, opts...); err != nil {
+		return nil, err
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.List(ctx, configMaps, opts...); err != nil {
+		return nil, err
+	}
+	objects := map[string]client.Object{}
+	for i := range secrets.Items {
+		if replication.IsCopy(&secrets.Items[i], nc) {
+			objects["Secret/"+secrets.Items[i].GetName()] = &secrets.Items[i]
+		}
+	}
+	for i := range configMaps.Items {
+		if replication.IsCopy(&configMaps.Items[i], nc) {
+			objects["ConfigMap/"+configMaps.Items[i].GetName()] = &configMaps.Items[i]
+		}
+	}
+	return objects, nil
+}
+
+// pruneReplicas deletes the copies of the namespace that are not desired
+func (r *NamespaceconfigReconciler) pruneReplicas(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName string, desired map[string]bool) error {
+	replicas, err := r.replicasOf(ctx, nc, namespaceName)
+	if err != nil {
+		return err
+	}
+	for _, key := range replication.Stale(replicas, desired) {
+		util.Logs.Info("Deleting ", key, " no longer replicated into namespace ", namespaceName)
+		if err := r.Delete(ctx, replicas[key]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// replicationSources passes the events of the Secrets and ConfigMaps of the
+// replication namespace that are or were marked for replication
+func (r *NamespaceconfigReconciler) replicationSources() predicate.Funcs {
+	isSource := func(obj client.Object) bool {
+		_, ok := obj.GetAnnotations()[namespaceconfigv2.ReplicateAnnotation]
+		return ok && obj.GetNamespace() == r.ReplicationNamespace
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isSource(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isSource(e.ObjectOld) || isSource(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isSource(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isSource(e.Object) },
+	}
+}
+
+// sourceToNamespaceconfigs maps a replication source to the Namespaceconfigs
+// whose namespace it selects and to the ones holding a copy of it, whose copies
+// may have to be created, updated or deleted
+func (r *NamespaceconfigReconciler) sourceToNamespaceconfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &namespaceconfigv2.NamespaceconfigList{}
+	if err := r.List(ctx, list); err != nil {
+		util.Logs.Error("Failed to list Namespaceconfigs for replication source ", obj.GetNamespace(), "/", obj.GetName(), ". Error: ", err)
+		return nil
+	}
+	names := map[string]bool{}
+	for _, nc := range list.Items {
+		if nc.Status.NamespaceName != "" && replication.Replicates(obj, nc.Spec.Environment, nc.Status.AppliedSize) {
+			names[nc.GetName()] = true
+		}
+	}
+	copies, err := r.copiesOf(ctx, obj)
+	if err != nil {
+		util.Logs.Error("Failed to list the copies of replication source ", obj.GetNamespace(), "/", obj.GetName(), ". Error: ", err)
+	}
+	for _, name := range replication.Holders(copies, obj.GetNamespace()+"/"+obj.GetName()) {
+		names[name] = true
+	}
+	requests := []reconcile.Request{}
+	for name := range names {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+	return requests
+}
+
+// copiesOf lists the copies of the kind of source in every namespace. Only the
+// labelled objects are listed.
+func (r *NamespaceconfigReconciler) copiesOf(ctx context.Context, source client.Object) ([]metav1.Object, error) {
+	labelled := client.MatchingLabels{namespaceconfigv2.ReplicatedLabel: "true"}
+	copies := []metav1.Object{}
+	switch source.(type) {
+	case *corev1.Secret:
+		secrets := &corev1.SecretList{}
+		if err := r.List(ctx, secrets, labelled); err != nil {
+			return nil, err
+		}
+		for i := range secrets.Items {
+			copies = append(copies, &secrets.Items[i])
+		}
+	case *corev1.ConfigMap:
+		configMaps := &corev1.ConfigMapList{}
+		if err := r.List(ctx, configMaps, labelled); err != nil {
+			return nil, err
+		}
+		for i := range configMaps.Items {
+			copies = append(copies, &configMaps.Items[i])
+		}
+	}
+	return copies, nil
+}
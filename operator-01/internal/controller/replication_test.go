@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/replication"
+)
+
+var _ = Describe("Replication", func() {
+	const shared = "shared"
+	var (
+		ctx    context.Context
+		source *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		source = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pull",
+				Namespace: shared,
+				Annotations: map[string]string{
+					namespaceconfigv2.ReplicateAnnotation:             "true",
+					namespaceconfigv2.ReplicateEnvironmentsAnnotation: "prod",
+				},
+			},
+			Data: map[string][]byte{"token": []byte("v1")},
+		}
+	})
+
+	It("maps a source to the Namespaceconfigs it selects or that hold a copy of it", func() {
+		provisioned := func(name, environment string) *namespaceconfigv2.Namespaceconfig {
+			nc := testNamespaceconfig(name, name[:3], environment)
+			nc.Status.NamespaceName = name[:3] + "-" + environment
+			return nc
+		}
+		payments, billing, holder := provisioned("payments", "prod"), provisioned("billing", "dev"), provisioned("holder", "dev")
+		pending := testNamespaceconfig("pending", "pen", "prod")
+		copied := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "pull",
+			Namespace:   "hol-dev",
+			Labels:      map[string]string{namespaceconfigv2.ReplicatedLabel: "true"},
+			Annotations: map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: "shared/pull"},
+		}}
+		r := fakeReconciler(append([]client.Object{payments, billing, holder, pending, source}, controlled(holder, copied)...)...)
+		r.ReplicationNamespace = shared
+
+		Expect(r.sourceToNamespaceconfigs(ctx, source)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "payments"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "holder"}},
+		))
+	})
+
+	It("does not count the update of a copy after a source change as drift", func() {
+		nc := testNamespaceconfig("payments", "pay", "prod")
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-prod"}}
+		copied := &corev1.Secret{ObjectMeta: replication.Meta(source, "pay-prod")}
+		recorder := record.NewFakeRecorder(10)
+		r := fakeReconciler(append([]client.Object{nc, namespace, source}, controlled(nc, copied)...)...)
+		r.Recorder = recorder
+		r.ReplicationNamespace = shared
+		p := &provisioning{nc: nc, namespace: namespace, size: "s", provisioned: true}
+
+		Expect(r.replicate(ctx, p)).To(BeNil())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(copied), copied)).To(Succeed())
+		Expect(copied.Data).To(HaveKeyWithValue("token", []byte("v1")))
+
+		By("Updating the source")
+		source.Data["token"] = []byte("v2")
+		Expect(r.Update(ctx, source)).To(Succeed())
+		Expect(r.replicate(ctx, p)).To(BeNil())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(copied), copied)).To(Succeed())
+		Expect(copied.Data).To(HaveKeyWithValue("token", []byte("v2")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/sizing"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// childName is the name of the LimitRange and the ResourceQuota of a size
+func childName(namespaceName, size string) string {
+	return namespaceName + "-limits-" + size
+}
+
+// effectiveSize returns the LimitRange and ResourceQuota of the size profile
+// merged with the overrides of the Namespaceconfig
+func (r *NamespaceconfigReconciler) effectiveSize(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, size string) (corev1.LimitRangeSpec, corev1.ResourceQuotaSpec, error) {
+	profile := &namespaceconfigv1.NamespaceSizeProfile{}
+	if err := r.Get(ctx, client.ObjectKey{Name: size}, profile); err != nil {
+		return corev1.LimitRangeSpec{}, corev1.ResourceQuotaSpec{}, err
+	}
+	limits, quota, errs := sizing.Effective(nc, profile)
+	if len(errs) > 0 {
+		return corev1.LimitRangeSpec{}, corev1.ResourceQuotaSpec{}, &sizing.InvalidError{Size: size, Errs: errs}
+	}
+	return limits, quota, nil
+}
+
+// usageAboveQuota lists the resources whose current usage, as tracked by the
+// ResourceQuota of the applied size, is above the hard limit of quota
+func (r *NamespaceconfigReconciler) usageAboveQuota(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName string, quota corev1.ResourceQuotaSpec) ([]string, error) {
+	current := &corev1.ResourceQuota{}
+	err := r.Get(ctx, client.ObjectKey{Name: childName(namespaceName, nc.Status.AppliedSize), Namespace: namespaceName}, current)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	exceeded := []string{}
+	for name, hard := range quota.Hard {
+		if used, ok := current.Status.Used[name]; ok && used.Cmp(hard) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s (used %s, new hard %s)", name, used.String(), hard.String()))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded, nil
+}
+
+// cleanupSizes deletes the LimitRanges and ResourceQuotas controlled by the
+// Namespaceconfig that belong to another size than size
+func (r *NamespaceconfigReconciler) cleanupSizes(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName, size string) error {
+	log := util.Logs
+	limits := &corev1.LimitRangeList{}
+	if err := r.List(ctx, limits, client.InNamespace(namespaceName)); err != nil {
+		return err
+	}
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(namespaceName)); err != nil {
+		return err
+	}
+	children := []client.Object{}
+	for i := range limits.Items {
+		children = append(children, &limits.Items[i])
+	}
+	for i := range quotas.Items {
+		children = append(children, &quotas.Items[i])
+	}
+	for _, child := range children {
+		if !metav1.IsControlledBy(child, nc) || child.GetName() == childName(namespaceName, size) {
+			continue
+		}
+		log.Info("Deleting ", child.GetName(), " of a previous size of Namespaceconfig ", nc.GetName())
+		if err := r.Delete(ctx, child); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("Resize", func() {
+	const nsname = "rsz-dev"
+	var (
+		ctx context.Context
+		nc  *namespaceconfigv2.Namespaceconfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		nc = testNamespaceconfig("resize", "rsz", "dev")
+		nc.Spec.Size = "s"
+		nc.Status.AppliedSize = "m"
+	})
+
+	It("refuses a shrink below the current usage and clears the refusal once reverted", func() {
+		small, err := ReadNamespaceSizeProfileFromFile("namespacesizeprofile_s.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		medium, err := ReadNamespaceSizeProfileFromFile("namespacesizeprofile_m.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		current := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "m"), Namespace: nsname},
+			Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("300"),
+			}},
+		}
+		r := fakeReconciler(small, medium, current)
+		p := &provisioning{nc: nc, namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsname}}}
+
+		Expect(r.resolveSize(ctx, p)).To(BeNil())
+		Expect(p.size).To(Equal("m"))
+		Expect(p.resizing).To(BeFalse())
+		resized := meta.FindStatusCondition(nc.Status.Conditions, namespaceconfigv2.ConditionResized)
+		Expect(resized).NotTo(BeNil())
+		Expect(resized.Status).To(Equal(metav1.ConditionFalse))
+		Expect(resized.Reason).To(Equal("UsageAboveQuota"))
+		Expect(resized.Message).To(ContainSubstring("pods (used 300, new hard 200)"))
+
+		By("Reverting spec.size to the applied size")
+		nc.Spec.Size = "m"
+		Expect(r.resolveSize(ctx, p)).To(BeNil())
+		Expect(p.size).To(Equal("m"))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, namespaceconfigv2.ConditionResized)).To(BeNil())
+	})
+
+	It("deletes the LimitRange and ResourceQuota of the previous size only", func() {
+		objects := append(controlled(nc,
+			&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "s"), Namespace: nsname}},
+			&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "s"), Namespace: nsname}},
+			&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "m"), Namespace: nsname}},
+			&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: childName(nsname, "m"), Namespace: nsname}},
+		), &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "team-quota", Namespace: nsname}})
+		r := fakeReconciler(objects...)
+
+		Expect(r.cleanupSizes(ctx, nc, nsname, "m")).To(Succeed())
+		Expect(errors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(objects[0]), &corev1.LimitRange{}))).To(BeTrue())
+		Expect(errors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(objects[1]), &corev1.ResourceQuota{}))).To(BeTrue())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(objects[2]), &corev1.LimitRange{})).To(Succeed())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(objects[3]), &corev1.ResourceQuota{})).To(Succeed())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(objects[4]), &corev1.ResourceQuota{})).To(Succeed())
+	})
+})
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// forceDeleteAnnotation on a Namespaceconfig deletes its namespace even when
+// the safety checks find data or traffic in it
+const forceDeleteAnnotation = "namespaceconfig.myoperator.io/force-delete"
+
+// forceDelete reports whether the safety checks are overridden for obj
+func forceDelete(obj client.Object) bool {
+	return obj.GetAnnotations()[forceDeleteAnnotation] == "true"
+}
+
+// reader returns the client used for the safety checks. Contents of namespaces
+// are read from the API server, caching every pod of the cluster is too costly.
+func (r *NamespaceconfigReconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// deletionHazards lists what deleting the namespace would destroy: bound
+// PersistentVolumeClaims, running pods, LoadBalancer services and any object
+// of the resources in the deny list
+func (r *NamespaceconfigReconciler) deletionHazards(ctx context.Context, namespaceName string) ([]string, error) {
+	reader := r.reader()
+	hazards := []string{}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := reader.List(ctx, pvcs, client.InNamespace(namespaceName)); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase == corev1.ClaimBound {
+			names = append(names, pvc.GetName())
+		}
+	}
+	hazards = appendHazard(hazards, "bound PersistentVolumeClaims", names)
+
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(namespaceName)); err != nil {
+		return nil, err
+	}
+	names = []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			names = append(names, pod.GetName())
+		}
+	}
+	hazards = appendHazard(hazards, "running pods", names)
+
+	services := &corev1.ServiceList{}
+	if err := reader.List(ctx, services, client.InNamespace(namespaceName)); err != nil {
+		return nil, err
+	}
+	names = []string{}
+	for _, service := range services.Items {
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			names = append(names, service.GetName())
+		}
+	}
+	hazards = appendHazard(hazards, "LoadBalancer services", names)
+
+	for _, gr := range r.DeletionDenyList {
+		// an unknown resource fails the check, a typo must not disable it
+		gvk, err := r.RESTMapper().KindFor(gr.WithVersion(""))
+		if err != nil {
+			return nil, fmt.Errorf("resource %s of the deletion deny list: %w", gr.String(), err)
+		}
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := reader.List(ctx, list, client.InNamespace(namespaceName)); err != nil {
+			return nil, err
+		}
+		names = []string{}
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		hazards = appendHazard(hazards, gr.String(), names)
+	}
+	return hazards, nil
+}
+
+// appendHazard adds what and the first few names to hazards when names is not empty
+func appendHazard(hazards []string, what string, names []string) []string {
+	if len(names) == 0 {
+		return hazards
+	}
+	sort.Strings(names)
+	shown := names
+	if len(shown) > 3 {
+		shown = append(shown[:3:3], "...")
+	}
+	return append(hazards, fmt.Sprintf("%d %s (%s)", len(names), what, strings.Join(shown, ", ")))
+}
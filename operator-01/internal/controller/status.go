@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/sizing"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// blockedRequeue is how long a blocked Namespaceconfig waits before it is checked again
+const blockedRequeue = time.Minute
+
+// setCondition sets a condition of the Namespaceconfig for its current generation
+func setCondition(nc *namespaceconfigv2.Namespaceconfig, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&nc.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: nc.GetGeneration(),
+	})
+}
+
+// setStep records the state of a provisioning step. The transition time only
+// changes with the state.
+func setStep(nc *namespaceconfigv2.Namespaceconfig, name string, state namespaceconfigv2.StepState, message string) {
+	for i := range nc.Status.Steps {
+		step := &nc.Status.Steps[i]
+		if step.Name != name {
+			continue
+		}
+		if step.State != state {
+			step.State = state
+			step.LastTransitionTime = metav1.Now()
+		}
+		step.Message = message
+		return
+	}
+	nc.Status.Steps = append(nc.Status.Steps, namespaceconfigv2.ProvisioningStep{
+		Name:               name,
+		State:              state,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+}
+
+// updateStatus writes the status of the Namespaceconfig when it differs from original
+func (r *NamespaceconfigReconciler) updateStatus(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, original *namespaceconfigv2.NamespaceconfigStatus) error {
+	nc.Status.ObservedGeneration = nc.GetGeneration()
+	if equality.Semantic.DeepEqual(original, &nc.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, nc); err != nil {
+		return err
+	}
+	util.Logs.Info("Namespaceconfig ", nc.GetName(), " status updated")
+	return nil
+}
+
+// profileReason is the condition reason of a failure to get or merge a size profile
+func profileReason(err error) string {
+	var invalid *sizing.InvalidError
+	switch {
+	case goerrors.Is(err, sizing.ErrNoSize):
+		return "SizeMissing"
+	case goerrors.As(err, &invalid):
+		return "ProfileInvalid"
+	case errors.IsNotFound(err):
+		return "ProfileNotFound"
+	}
+	return "ProfileUnavailable"
+}
+
+// failed reports err with reason on the given conditions and on Ready, writes
+// the status and returns err, so that the reconcile is retried
+func (r *NamespaceconfigReconciler) failed(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, original *namespaceconfigv2.NamespaceconfigStatus, reason string, err error, conditionTypes ...string) (ctrl.Result, error) {
+	for _, conditionType := range append(conditionTypes, namespaceconfigv2.ConditionReady) {
+		setCondition(nc, conditionType, metav1.ConditionFalse, reason, err.Error())
+	}
+	if statusErr := r.updateStatus(ctx, nc, original); statusErr != nil {
+		util.Logs.Error("Failed to update status of Namespaceconfig ", nc.GetName(), ". Error: ", statusErr)
+	}
+	if isTerminal(err) {
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+	return ctrl.Result{}, err
+}
+
+// isTerminal reports errors that retrying cannot fix. They go away with a
+// change of the Namespaceconfig or of its profile, which both trigger a reconcile.
+func isTerminal(err error) bool {
+	var invalid *sizing.InvalidError
+	return goerrors.Is(err, sizing.ErrNoSize) || goerrors.As(err, &invalid)
+}
+
+// blocked reports a state that retrying right away cannot fix, such as a name
+// conflict, with reason and message on the given conditions and on Ready. The
+// status is written and the Namespaceconfig is checked again after a while.
+func (r *NamespaceconfigReconciler) blocked(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, original *namespaceconfigv2.NamespaceconfigStatus, reason, message string, conditionTypes ...string) (ctrl.Result, error) {
+	for _, conditionType := range append(conditionTypes, namespaceconfigv2.ConditionReady) {
+		setCondition(nc, conditionType, metav1.ConditionFalse, reason, message)
+	}
+	util.Logs.Error(message, " for Namespaceconfig ", nc.GetName())
+	if r.Recorder != nil {
+		r.Recorder.Event(nc, corev1.EventTypeWarning, reason, message)
+	}
+	return ctrl.Result{RequeueAfter: blockedRequeue}, r.updateStatus(ctx, nc, original)
+}
@@ -36,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -43,9 +44,8 @@ import (
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
 var (
-	k8sClient   client.Client
-	testEnv     *envtest.Environment
-	testCluster string
+	k8sClient client.Client
+	testEnv   *envtest.Environment
 )
 
 func TestControllers(t *testing.T) {
@@ -56,6 +56,21 @@ func TestControllers(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+	err := namespaceconfigv1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+	err = namespaceconfigv2.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	//+kubebuilder:scaffold:scheme
+})
+
+// useTestCluster connects k8sClient to the cluster of TEST_CLUSTER or to an
+// envtest control plane. Only the specs that need an API server call it, in a
+// BeforeAll, so that the specs on a fake client run without the envtest binaries.
+func useTestCluster() {
+	if k8sClient != nil {
+		return
+	}
 	testCluster := os.ExpandEnv("${TEST_CLUSTER}")
 	if testCluster != "" {
 		fmt.Println("Running test on", testCluster, " cluster.")
@@ -65,8 +80,6 @@ var _ = BeforeSuite(func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(k8sClient).NotTo(BeNil())
 
-		err = namespaceconfigv1.AddToScheme(scheme.Scheme)
-		Expect(err).NotTo(HaveOccurred())
 		err = admissionv1beta1.AddToScheme(scheme.Scheme)
 		Expect(err).NotTo(HaveOccurred())
 		err = apiextensions.AddToScheme(scheme.Scheme)
@@ -93,20 +106,14 @@ var _ = BeforeSuite(func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(cfg).NotTo(BeNil())
 
-		err = namespaceconfigv1.AddToScheme(scheme.Scheme)
-		Expect(err).NotTo(HaveOccurred())
-
-		//+kubebuilder:scaffold:scheme
-
 		k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(k8sClient).NotTo(BeNil())
 	}
-
-})
+}
 
 var _ = AfterSuite(func() {
-	if os.ExpandEnv("${TEST_CLUSTER}") == "" {
+	if testEnv != nil {
 		By("tearing down the test environment")
 		err := testEnv.Stop()
 		Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/templates"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// templateObjectKey identifies an object applied from a NamespaceTemplate
+// regardless of the template
+type templateObjectKey struct {
+	apiVersion, kind, name string
+}
+
+func keyOf(ref namespaceconfigv2.TemplateObject) templateObjectKey {
+	return templateObjectKey{ref.APIVersion, ref.Kind, ref.Name}
+}
+
+// renderTemplates renders the NamespaceTemplates selected by nc, in the order
+// of their names. Only namespaced objects can be rendered and no two
+// templates may render the same object.
+func (r *NamespaceconfigReconciler) renderTemplates(ctx context.Context, p *provisioning) ([]*unstructured.Unstructured, []namespaceconfigv2.TemplateObject, *stepError) {
+	conditions := []string{namespaceconfigv2.ConditionTemplatesApplied}
+	list := &namespaceconfigv2.NamespaceTemplateList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, nil, &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	data := templates.DataFor(p.nc, p.namespace.GetName(), p.size)
+	objects := []*unstructured.Unstructured{}
+	refs := []namespaceconfigv2.TemplateObject{}
+	rendered := map[templateObjectKey]string{}
+	for i := range list.Items {
+		tpl := &list.Items[i]
+		selected, err := templates.Selects(p.nc, tpl)
+		if err != nil {
+			return nil, nil, &stepError{reason: "TemplateInvalid", err: err, conditions: conditions, blocked: true}
+		}
+		if !selected {
+			continue
+		}
+		tplObjects, err := templates.Render(tpl, data)
+		if err != nil {
+			return nil, nil, &stepError{reason: "TemplateInvalid", err: err, conditions: conditions, blocked: true}
+		}
+		for _, obj := range tplObjects {
+			gvk := obj.GroupVersionKind()
+			mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				return nil, nil, &stepError{reason: "TemplateInvalid", err: err, conditions: conditions, blocked: true}
+			}
+			if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				return nil, nil, &stepError{reason: "TemplateInvalid", conditions: conditions, blocked: true,
+					err: fmt.Errorf("NamespaceTemplate %s renders the cluster-scoped %s %s", tpl.GetName(), obj.GetKind(), obj.GetName())}
+			}
+			ref := namespaceconfigv2.TemplateObject{Template: tpl.GetName(), APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName()}
+			if other, ok := rendered[keyOf(ref)]; ok {
+				return nil, nil, &stepError{reason: "TemplateInvalid", conditions: conditions, blocked: true,
+					err: fmt.Errorf("%s %s is rendered by both NamespaceTemplates %s and %s", obj.GetKind(), obj.GetName(), other, tpl.GetName())}
+			}
+			rendered[keyOf(ref)] = tpl.GetName()
+			if err := ctrl.SetControllerReference(p.nc, obj, r.Scheme); err != nil {
+				return nil, nil, &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+			}
+			objects = append(objects, obj)
+			refs = append(refs, ref)
+		}
+	}
+	return objects, refs, nil
+}
+
+// applyTemplates applies the objects of the selected NamespaceTemplates and
+// deletes the ones they no longer render. The applied objects are recorded in
+// the status before anything is deleted, so that an object is never lost
+// track of when a reconcile fails halfway.
+func (r *NamespaceconfigReconciler) applyTemplates(ctx context.Context, p *provisioning) *stepError {
+	conditions := []string{namespaceconfigv2.ConditionTemplatesApplied}
+	objects, refs, stepErr := r.renderTemplates(ctx, p)
+	if stepErr != nil {
+		return stepErr
+	}
+	previous := map[templateObjectKey]bool{}
+	for _, ref := range p.nc.Status.TemplateObjects {
+		previous[keyOf(ref)] = true
+	}
+	desired := map[templateObjectKey]bool{}
+	for _, ref := range refs {
+		desired[keyOf(ref)] = true
+	}
+	stale := []namespaceconfigv2.TemplateObject{}
+	for _, ref := range p.nc.Status.TemplateObjects {
+		if !desired[keyOf(ref)] {
+			stale = append(stale, ref)
+		}
+	}
+	p.nc.Status.TemplateObjects = append(append([]namespaceconfigv2.TemplateObject{}, refs...), stale...)
+
+	for i, obj := range objects {
+		if err := r.applyChild(ctx, p.nc, obj, p.provisioned && previous[keyOf(refs[i])]); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+	for _, ref := range stale {
+		if err := r.deleteTemplateObject(ctx, p.nc, p.namespace.GetName(), ref); err != nil {
+			return &stepError{reason: "ApplyFailed", err: err, conditions: conditions}
+		}
+	}
+	p.nc.Status.TemplateObjects = refs
+	setCondition(p.nc, namespaceconfigv2.ConditionTemplatesApplied, metav1.ConditionTrue, "Applied",
+		fmt.Sprintf("%d objects of NamespaceTemplates are applied", len(refs)))
+	return nil
+}
+
+// templateObject gets an object applied from a NamespaceTemplate, nil when it
+// is gone or no longer controlled by the Namespaceconfig
+func (r *NamespaceconfigReconciler) templateObject(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName string, ref namespaceconfigv2.TemplateObject) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	err := r.Get(ctx, client.ObjectKey{Namespace: namespaceName, Name: ref.Name}, obj)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !metav1.IsControlledBy(obj, nc) {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// deleteTemplateObject deletes an object a NamespaceTemplate no longer renders
+func (r *NamespaceconfigReconciler) deleteTemplateObject(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig, namespaceName string, ref namespaceconfigv2.TemplateObject) error {
+	obj, err := r.templateObject(ctx, nc, namespaceName, ref)
+	if err != nil || obj == nil {
+		return err
+	}
+	util.Logs.Info("Deleting ", ref.Kind, " ", ref.Name, " no longer rendered for Namespaceconfig ", nc.GetName())
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// templateToNamespaceconfigs maps a NamespaceTemplate to the Namespaceconfigs
+// selecting it and to the ones holding objects it rendered before
+func (r *NamespaceconfigReconciler) templateToNamespaceconfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	tpl, ok := obj.(*namespaceconfigv2.NamespaceTemplate)
+	if !ok {
+		return nil
+	}
+	ncs := &namespaceconfigv2.NamespaceconfigList{}
+	if err := r.List(ctx, ncs); err != nil {
+		util.Logs.Error("Failed to list Namespaceconfigs for NamespaceTemplate ", tpl.GetName(), ". Error: ", err)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for i := range ncs.Items {
+		nc := &ncs.Items[i]
+		selected, _ := templates.Selects(nc, tpl)
+		for _, ref := range nc.Status.TemplateObjects {
+			selected = selected || ref.Template == tpl.GetName()
+		}
+		if selected {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: nc.GetName()}})
+		}
+	}
+	return requests
+}
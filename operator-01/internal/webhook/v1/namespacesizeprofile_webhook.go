@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/sizing"
+)
+
+// namespaceSizeProfileKind is the kind reported in the errors of the webhook
+var namespaceSizeProfileKind = namespaceconfigv1.GroupVersion.WithKind("NamespaceSizeProfile").GroupKind()
+
+// NamespaceSizeProfileValidator rejects NamespaceSizeProfiles whose LimitRange
+// and ResourceQuota contradict each other, before any Namespaceconfig uses them
+type NamespaceSizeProfileValidator struct{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (v *NamespaceSizeProfileValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&namespaceconfigv1.NamespaceSizeProfile{}).
+		WithValidator(v).
+		RecoverPanic().
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-namespaceconfig-myoperator-io-v1-namespacesizeprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=namespaceconfig.myoperator.io,resources=namespacesizeprofiles,verbs=create;update,versions=v1,name=vnamespacesizeprofile.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &NamespaceSizeProfileValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *NamespaceSizeProfileValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateProfile(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *NamespaceSizeProfileValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateProfile(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *NamespaceSizeProfileValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateProfile returns an invalid error listing the contradictions of the profile
+func validateProfile(obj runtime.Object) error {
+	profile, ok := obj.(*namespaceconfigv1.NamespaceSizeProfile)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a NamespaceSizeProfile but got a %T", obj))
+	}
+	if allErrs := sizing.ValidateProfile(profile); len(allErrs) > 0 {
+		return apierrors.NewInvalid(namespaceSizeProfileKind, profile.GetName(), allErrs)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+)
+
+var _ = Describe("NamespaceSizeProfile validation", func() {
+	var (
+		ctx     context.Context
+		profile *namespaceconfigv1.NamespaceSizeProfile
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		profile = &namespaceconfigv1.NamespaceSizeProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "s"},
+			Spec: namespaceconfigv1.NamespaceSizeProfileSpec{
+				LimitRange: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+					Type:           corev1.LimitTypeContainer,
+					Max:            corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					Default:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				}}},
+				ResourceQuota: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+					corev1.ResourceLimitsCPU: resource.MustParse("4"),
+				}},
+			},
+		}
+	})
+
+	It("accepts a profile whose LimitRange fits into its ResourceQuota", func() {
+		validator := &NamespaceSizeProfileValidator{}
+		_, err := validator.ValidateCreate(ctx, profile)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a contradicting profile on creation and update", func() {
+		validator := &NamespaceSizeProfileValidator{}
+		updated := profile.DeepCopy()
+		updated.Spec.ResourceQuota.Hard[corev1.ResourceLimitsCPU] = resource.MustParse("1")
+		_, err := validator.ValidateCreate(ctx, updated)
+		Expect(err).To(MatchError(ContainSubstring("spec.LimitRange.limits[0].max[cpu]")))
+		_, err = validator.ValidateUpdate(ctx, profile, updated)
+		Expect(err).To(HaveOccurred())
+	})
+})
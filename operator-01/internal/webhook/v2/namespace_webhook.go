@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+)
+
+// namespaceWebhookPath is the path of the webhook validating namespace deletions
+const namespaceWebhookPath = "/validate--v1-namespace"
+
+// NamespaceValidator rejects the deletion of namespaces whose Namespaceconfig
+// is protected against deletion
+type NamespaceValidator struct {
+	Client client.Reader
+	// ProtectedEnvironments are the environments where DeletionProtection defaults to true
+	ProtectedEnvironments config.Environments
+}
+
+// SetupWithManager registers the webhook with the Manager.
+func (v *NamespaceValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(namespaceWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// The webhook ignores failures, otherwise no namespace of the cluster could be
+// deleted while the operator is down. The finalizer of a protected
+// Namespaceconfig still keeps its namespace in that case.
+//+kubebuilder:webhook:path=/validate--v1-namespace,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=namespaces,verbs=delete,versions=v1,name=vnamespace.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.Handler = &NamespaceValidator{}
+
+// Handle implements admission.Handler
+func (v *NamespaceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	ncs := &namespaceconfigv2.NamespaceconfigList{}
+	if err := v.Client.List(ctx, ncs); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	for i := range ncs.Items {
+		nc := &ncs.Items[i]
+		if namespaceName(nc) != req.Name || !config.DeletionProtected(nc, v.ProtectedEnvironments) {
+			continue
+		}
+		return admission.Denied(fmt.Sprintf(
+			"namespace %s is protected against deletion by Namespaceconfig %s, set spec.deletionProtection of the Namespaceconfig to false first",
+			req.Name, nc.GetName()))
+	}
+	return admission.Allowed("")
+}
+
+// namespaceName is the namespace managed by a Namespaceconfig
+func namespaceName(nc *namespaceconfigv2.Namespaceconfig) string {
+	if nc.Status.NamespaceName != "" {
+		return nc.Status.NamespaceName
+	}
+	return nc.Spec.Abbreviation + "-" + nc.Spec.Environment
+}
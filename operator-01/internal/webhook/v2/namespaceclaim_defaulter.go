@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// NamespaceClaimDefaulter completes NamespaceClaims on admission. The
+// Namespaceconfig of a claim is created by the operator, so the tenant
+// creating the claim is recorded as owner here.
+type NamespaceClaimDefaulter struct{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (d *NamespaceClaimDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&namespaceconfigv2.NamespaceClaim{}).
+		WithDefaulter(d).
+		RecoverPanic().
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-namespaceconfig-myoperator-io-v2-namespaceclaim,mutating=true,failurePolicy=fail,sideEffects=None,groups=namespaceconfig.myoperator.io,resources=namespaceclaims,verbs=create,versions=v2,name=mnamespaceclaim.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.CustomDefaulter = &NamespaceClaimDefaulter{}
+
+// Default implements admission.CustomDefaulter. It sets the requesting user as owner.
+func (d *NamespaceClaimDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	claim, ok := obj.(*namespaceconfigv2.NamespaceClaim)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a NamespaceClaim but got a %T", obj))
+	}
+	if len(claim.Spec.Owners) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil && req.UserInfo.Username != "" {
+			claim.Spec.Owners = []namespaceconfigv2.Owner{owner(req.UserInfo.Username)}
+		}
+	}
+	return nil
+}
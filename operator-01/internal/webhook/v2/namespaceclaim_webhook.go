@@ -0,0 +1,196 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+)
+
+// namespaceClaimKind is the kind reported in the errors of the webhook
+var namespaceClaimKind = namespaceconfigv2.GroupVersion.WithKind("NamespaceClaim").GroupKind()
+
+// NamespaceClaimValidator validates NamespaceClaims on admission. A tenant may
+// only make owners of a provisioned namespace the requester, the groups of the
+// requester and the service accounts of the namespace of the claim.
+type NamespaceClaimValidator struct {
+	Client client.Reader
+}
+
+// SetupWithManager registers the webhook with the Manager.
+func (v *NamespaceClaimValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&namespaceconfigv2.NamespaceClaim{}).
+		WithValidator(v).
+		RecoverPanic().
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-namespaceconfig-myoperator-io-v2-namespaceclaim,mutating=false,failurePolicy=fail,sideEffects=None,groups=namespaceconfig.myoperator.io,resources=namespaceclaims,verbs=create;update,versions=v2,name=vnamespaceclaim.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &NamespaceClaimValidator{}
+
+// ValidateCreate implements admission.CustomValidator. The name of the claim is
+// immutable, so the name of its Namespaceconfig is only checked on creation.
+func (v *NamespaceClaimValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	claim, err := asNamespaceClaim(obj)
+	if err != nil {
+		return nil, err
+	}
+	allErrs := validateClaimOwners(ctx, claim, nil)
+	if claim.Spec.NamespaceconfigName == "" {
+		names, err := v.validateClaimedName(ctx, claim)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, names...)
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(namespaceClaimKind, claim.GetName(), allErrs)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.CustomValidator. The owners already on the
+// claim stay allowed, whoever updates it.
+func (v *NamespaceClaimValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldClaim, err := asNamespaceClaim(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newClaim, err := asNamespaceClaim(newObj)
+	if err != nil {
+		return nil, err
+	}
+	if equality.Semantic.DeepEqual(oldClaim.Spec.Owners, newClaim.Spec.Owners) {
+		return nil, nil
+	}
+	if allErrs := validateClaimOwners(ctx, newClaim, oldClaim.Spec.Owners); len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(namespaceClaimKind, newClaim.GetName(), allErrs)
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *NamespaceClaimValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// asNamespaceClaim returns obj as a NamespaceClaim
+func asNamespaceClaim(obj runtime.Object) (*namespaceconfigv2.NamespaceClaim, error) {
+	claim, ok := obj.(*namespaceconfigv2.NamespaceClaim)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a NamespaceClaim but got a %T", obj))
+	}
+	return claim, nil
+}
+
+// validateClaimOwners rejects the owners of a claim that are not previous owners
+// and that the requester may not grant access to
+func validateClaimOwners(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim, previous []namespaceconfigv2.Owner) field.ErrorList {
+	user := authenticationv1.UserInfo{}
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		user = req.UserInfo
+	}
+	allErrs := field.ErrorList{}
+	for i, o := range claim.Spec.Owners {
+		if sameOwner(o, previous) || grantable(o, claim.GetNamespace(), user) {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "owners").Index(i),
+			"a claim may only be owned by the requesting user, one of its groups or a service account of namespace "+claim.GetNamespace()))
+	}
+	return allErrs
+}
+
+// sameOwner reports whether owners holds the user, group or service account of o
+func sameOwner(o namespaceconfigv2.Owner, owners []namespaceconfigv2.Owner) bool {
+	for _, other := range owners {
+		if ownerKind(other) == ownerKind(o) && other.Name == o.Name && other.Namespace == o.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// grantable reports whether user may make candidate an owner of the namespace
+// of a claim in claimNamespace. The system groups, which every user of a kind
+// is in, are never grantable.
+func grantable(candidate namespaceconfigv2.Owner, claimNamespace string, user authenticationv1.UserInfo) bool {
+	if user.Username != "" && sameOwner(candidate, []namespaceconfigv2.Owner{owner(user.Username)}) {
+		return true
+	}
+	switch ownerKind(candidate) {
+	case namespaceconfigv2.OwnerGroup:
+		if strings.HasPrefix(candidate.Name, "system:") {
+			return false
+		}
+		for _, group := range user.Groups {
+			if group == candidate.Name {
+				return true
+			}
+		}
+	case namespaceconfigv2.OwnerServiceAccount:
+		// without a namespace it is a service account of the provisioned namespace
+		return candidate.Namespace == "" || candidate.Namespace == claimNamespace
+	}
+	return false
+}
+
+// ownerKind is the kind of owner, User when unset
+func ownerKind(o namespaceconfigv2.Owner) namespaceconfigv2.OwnerKind {
+	if o.Kind == "" {
+		return namespaceconfigv2.OwnerUser
+	}
+	return o.Kind
+}
+
+// validateClaimedName rejects a claim whose provisioned Namespaceconfig would be
+// longer than 63 characters, or would have the name of another one: namespace
+// a-b with claim c and namespace a with claim b-c both make a-b-c.
+func (v *NamespaceClaimValidator) validateClaimedName(ctx context.Context, claim *namespaceconfigv2.NamespaceClaim) (field.ErrorList, error) {
+	namePath := field.NewPath("metadata", "name")
+	name := config.ClaimedName(claim)
+	if len(name) > validation.LabelValueMaxLength {
+		return field.ErrorList{field.Invalid(namePath, claim.GetName(),
+			fmt.Sprintf("the Namespaceconfig %s of the claim must be no more than %d characters", name, validation.LabelValueMaxLength))}, nil
+	}
+	claims := &namespaceconfigv2.NamespaceClaimList{}
+	if err := v.Client.List(ctx, claims); err != nil {
+		return nil, err
+	}
+	for _, other := range claims.Items {
+		if other.GetNamespace() == claim.GetNamespace() && other.GetName() == claim.GetName() {
+			continue
+		}
+		if other.Spec.NamespaceconfigName == "" && config.ClaimedName(&other) == name {
+			return field.ErrorList{field.Invalid(namePath, claim.GetName(),
+				fmt.Sprintf("the Namespaceconfig %s of the claim is already claimed by NamespaceClaim %s/%s", name, other.GetNamespace(), other.GetName()))}, nil
+		}
+	}
+	nc := &namespaceconfigv2.Namespaceconfig{}
+	err := v.Client.Get(ctx, client.ObjectKey{Name: name}, nc)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	if nc.GetAnnotations()[namespaceconfigv2.ProvisionedByAnnotation] != claim.GetNamespace()+"/"+claim.GetName() {
+		return field.ErrorList{field.Invalid(namePath, claim.GetName(),
+			fmt.Sprintf("the Namespaceconfig %s of the claim already exists", name))}, nil
+	}
+	return nil, nil
+}
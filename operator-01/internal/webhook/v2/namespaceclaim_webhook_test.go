@@ -0,0 +1,107 @@
+package v2
+
+import (
+	"context"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("NamespaceClaim validation", func() {
+	var (
+		ctx   context.Context
+		claim *namespaceconfigv2.NamespaceClaim
+	)
+
+	validator := func(objs ...client.Object) *NamespaceClaimValidator {
+		return &NamespaceClaimValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+	}
+
+	BeforeEach(func() {
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{
+				Username: "alice",
+				Groups:   []string{"billing-team", "system:authenticated"},
+			},
+		}})
+		claim = &namespaceconfigv2.NamespaceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "team-billing"},
+			Spec:       namespaceconfigv2.NamespaceClaimSpec{Environment: "dev", Abbreviation: "bil"},
+		}
+	})
+
+	DescribeTable("owners the requester may grant",
+		func(o namespaceconfigv2.Owner, allowed bool) {
+			claim.Spec.Owners = []namespaceconfigv2.Owner{o}
+			_, err := validator().ValidateCreate(ctx, claim)
+			if allowed {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring("spec.owners[0]")))
+			}
+		},
+		Entry("the requesting user", namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerUser, Name: "alice"}, true),
+		Entry("the requesting user without a kind", namespaceconfigv2.Owner{Name: "alice"}, true),
+		Entry("another user", namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerUser, Name: "mallory"}, false),
+		Entry("a group of the requester", namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerGroup, Name: "billing-team"}, true),
+		Entry("a system group of the requester", namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerGroup, Name: "system:authenticated"}, false),
+		Entry("another group", namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerGroup, Name: "system:masters"}, false),
+		Entry("a service account of the claim namespace",
+			namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerServiceAccount, Namespace: "team-billing", Name: "deployer"}, true),
+		Entry("a service account of the provisioned namespace",
+			namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerServiceAccount, Name: "deployer"}, true),
+		Entry("a service account of another namespace",
+			namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerServiceAccount, Namespace: "kube-system", Name: "default"}, false),
+	)
+
+	It("accepts the requesting service account and keeps the owners already on the claim", func() {
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:ci:deployer"},
+		}})
+		claim.Spec.Owners = []namespaceconfigv2.Owner{{Kind: namespaceconfigv2.OwnerServiceAccount, Namespace: "ci", Name: "deployer"}}
+		_, err := validator().ValidateCreate(ctx, claim)
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := claim.DeepCopy()
+		updated.Spec.Owners = append(updated.Spec.Owners, namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerUser, Name: "mallory"})
+		_, err = validator().ValidateUpdate(ctx, claim, updated)
+		Expect(err).To(MatchError(ContainSubstring("spec.owners[1]")))
+		updated.Spec.Owners = updated.Spec.Owners[:1]
+		_, err = validator().ValidateUpdate(context.Background(), claim, updated)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a claim whose Namespaceconfig name is too long", func() {
+		claim.SetName(strings.Repeat("b", 60))
+		_, err := validator().ValidateCreate(ctx, claim)
+		Expect(err).To(MatchError(ContainSubstring("must be no more than 63 characters")))
+	})
+
+	It("rejects claims whose Namespaceconfig names collide", func() {
+		other := &namespaceconfigv2.NamespaceClaim{ObjectMeta: metav1.ObjectMeta{Name: "billing-billing", Namespace: "team"}}
+		_, err := validator(other).ValidateCreate(ctx, claim)
+		Expect(err).To(MatchError(ContainSubstring("already claimed by NamespaceClaim team/billing-billing")))
+
+		existing := &namespaceconfigv2.Namespaceconfig{ObjectMeta: metav1.ObjectMeta{Name: "team-billing-billing"}}
+		_, err = validator(existing).ValidateCreate(ctx, claim)
+		Expect(err).To(MatchError(ContainSubstring("Namespaceconfig team-billing-billing of the claim already exists")))
+
+		existing.SetAnnotations(map[string]string{namespaceconfigv2.ProvisionedByAnnotation: "team-billing/billing"})
+		_, err = validator(existing).ValidateCreate(ctx, claim)
+		Expect(err).NotTo(HaveOccurred())
+
+		claim.Spec.NamespaceconfigName = "reserved"
+		_, err = validator(other).ValidateCreate(ctx, claim)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
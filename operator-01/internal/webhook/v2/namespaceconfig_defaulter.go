@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"context"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+// Standard labels set on every Namespaceconfig, so that they can be selected by
+// environment, owner and size
+const (
+	environmentLabel = "namespaceconfig.myoperator.io/environment"
+	ownerLabel       = "namespaceconfig.myoperator.io/owner"
+	sizeLabel        = "namespaceconfig.myoperator.io/size"
+)
+
+// NamespaceconfigDefaulter completes Namespaceconfigs on admission
+type NamespaceconfigDefaulter struct {
+	// Sizes are the default size of each environment
+	Sizes config.PerEnvironment
+}
+
+// SetupWithManager registers the webhook with the Manager.
+func (d *NamespaceconfigDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&namespaceconfigv2.Namespaceconfig{}).
+		WithDefaulter(d).
+		RecoverPanic().
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-namespaceconfig-myoperator-io-v2-namespaceconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=namespaceconfig.myoperator.io,resources=namespaceconfigs,verbs=create;update,versions=v2,name=mnamespaceconfig.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.CustomDefaulter = &NamespaceconfigDefaulter{}
+
+// Default implements admission.CustomDefaulter. It sets the size of the
+// environment, the creating user as owner and the standard labels. The owner
+// label holds the first owner.
+func (d *NamespaceconfigDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	nc, err := asNamespaceconfig(obj)
+	if err != nil {
+		return err
+	}
+	if nc.Spec.Size == "" {
+		if size, ok := d.Sizes.For(nc.Spec.Environment); ok {
+			nc.Spec.Size = size
+		}
+	}
+	// only the creator becomes owner, an update by anyone else must not make them admin
+	if len(nc.Spec.Owners) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation == admissionv1.Create && req.UserInfo.Username != "" {
+			nc.Spec.Owners = []namespaceconfigv2.Owner{owner(req.UserInfo.Username)}
+		}
+	}
+	ownerName := ""
+	if len(nc.Spec.Owners) > 0 {
+		ownerName = nc.Spec.Owners[0].Name
+	}
+
+	labels := nc.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for key, value := range map[string]string{
+		environmentLabel: nc.Spec.Environment,
+		ownerLabel:       ownerName,
+		sizeLabel:        strings.ToLower(nc.Spec.Size),
+	} {
+		if value = util.LabelValue(value); value != "" {
+			labels[key] = value
+		} else {
+			delete(labels, key)
+		}
+	}
+	nc.SetLabels(labels)
+	return nil
+}
+
+// owner is the admin owner of a Namespaceconfig created by username: a service
+// account for system:serviceaccount:<namespace>:<name>, a user otherwise
+func owner(username string) namespaceconfigv2.Owner {
+	parts := strings.Split(username, ":")
+	if len(parts) == 4 && parts[0] == "system" && parts[1] == "serviceaccount" {
+		return namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerServiceAccount, Namespace: parts[2], Name: parts[3], Role: namespaceconfigv2.OwnerAdmin}
+	}
+	return namespaceconfigv2.Owner{Kind: namespaceconfigv2.OwnerUser, Name: username, Role: namespaceconfigv2.OwnerAdmin}
+}
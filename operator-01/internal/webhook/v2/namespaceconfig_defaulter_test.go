@@ -0,0 +1,90 @@
+package v2
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+)
+
+var _ = Describe("Namespaceconfig defaults", func() {
+	var (
+		ctx       context.Context
+		nc        *namespaceconfigv2.Namespaceconfig
+		defaulter *NamespaceconfigDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			UserInfo:  authenticationv1.UserInfo{Username: "system:serviceaccount:ci:deployer"},
+		}})
+		nc = &namespaceconfigv2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: namespaceconfigv2.NamespaceconfigSpec{
+				Abbreviation: "pay",
+				Environment:  "prod",
+			},
+		}
+		defaulter = &NamespaceconfigDefaulter{Sizes: config.PerEnvironment{"prod": "l", "*": "s"}}
+	})
+
+	It("fills in the size of the environment, the requesting user and the labels", func() {
+		Expect(defaulter.Default(ctx, nc)).To(Succeed())
+		Expect(nc.Spec.Size).To(Equal("l"))
+		Expect(nc.Spec.Owners).To(Equal([]namespaceconfigv2.Owner{{
+			Kind:      namespaceconfigv2.OwnerServiceAccount,
+			Namespace: "ci",
+			Name:      "deployer",
+			Role:      namespaceconfigv2.OwnerAdmin,
+		}}))
+		Expect(nc.GetLabels()).To(Equal(map[string]string{
+			environmentLabel: "prod",
+			ownerLabel:       "deployer",
+			sizeLabel:        "l",
+		}))
+	})
+
+	It("keeps the values that are set", func() {
+		nc.Spec.Size = "M"
+		nc.Spec.Owners = []namespaceconfigv2.Owner{
+			{Kind: namespaceconfigv2.OwnerGroup, Name: "payments-team", Role: namespaceconfigv2.OwnerAdmin},
+			{Kind: namespaceconfigv2.OwnerUser, Name: "alice@example.com", Role: namespaceconfigv2.OwnerView},
+		}
+		Expect(defaulter.Default(ctx, nc)).To(Succeed())
+		Expect(nc.Spec.Size).To(Equal("M"))
+		Expect(nc.Spec.Owners).To(HaveLen(2))
+		Expect(nc.GetLabels()).To(HaveKeyWithValue(ownerLabel, "payments-team"))
+		Expect(nc.GetLabels()).To(HaveKeyWithValue(sizeLabel, "m"))
+	})
+
+	It("does not make the user updating an ownerless Namespaceconfig its owner", func() {
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "cluster-admin"},
+		}})
+		Expect(defaulter.Default(ctx, nc)).To(Succeed())
+		Expect(nc.Spec.Owners).To(BeEmpty())
+		Expect(nc.GetLabels()).NotTo(HaveKey(ownerLabel))
+		Expect(nc.GetLabels()).To(HaveKeyWithValue(sizeLabel, "l"))
+	})
+})
+
+var _ = Describe("NamespaceClaim defaults", func() {
+	It("records the requesting user as owner", func() {
+		ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: "alice"},
+		}})
+		claim := &namespaceconfigv2.NamespaceClaim{ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "team-billing"}}
+		Expect((&NamespaceClaimDefaulter{}).Default(ctx, claim)).To(Succeed())
+		Expect(claim.Spec.Owners).To(Equal([]namespaceconfigv2.Owner{{Kind: namespaceconfigv2.OwnerUser, Name: "alice", Role: namespaceconfigv2.OwnerAdmin}}))
+	})
+})
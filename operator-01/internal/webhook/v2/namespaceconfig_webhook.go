@@ -0,0 +1,287 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/util"
+)
+
+var (
+	// namespaceconfigResource is the resource reported in the errors of the webhook
+	namespaceconfigResource = namespaceconfigv2.GroupVersion.WithResource("namespaceconfigs").GroupResource()
+	namespaceconfigKind     = namespaceconfigv2.GroupVersion.WithKind("Namespaceconfig").GroupKind()
+)
+
+// NamespaceconfigValidator validates Namespaceconfigs on admission
+type NamespaceconfigValidator struct {
+	Client   client.Reader
+	Recorder record.EventRecorder
+	// ProtectedEnvironments are the environments where DeletionProtection defaults to true
+	ProtectedEnvironments config.Environments
+	// AllowedEnvironments are the accepted values of Environment, any value is accepted when empty
+	AllowedEnvironments config.Environments
+	// ReservedPrefixes are prefixes namespace names must not start with
+	ReservedPrefixes config.List
+	// AccessRoles are the ClusterRoles access may grant
+	AccessRoles config.List
+}
+
+// SetupWithManager registers the webhook with the Manager.
+func (v *NamespaceconfigValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&namespaceconfigv2.Namespaceconfig{}).
+		WithValidator(v).
+		RecoverPanic().
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-namespaceconfig-myoperator-io-v2-namespaceconfig,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=namespaceconfig.myoperator.io,resources=namespaceconfigs,verbs=create;update;delete,versions=v2,name=vnamespaceconfig.myoperator.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &NamespaceconfigValidator{}
+
+// ValidateCreate implements admission.CustomValidator. The namespace name is
+// immutable, so it is only checked on creation.
+func (v *NamespaceconfigValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nc, err := asNamespaceconfig(obj)
+	if err != nil {
+		return nil, err
+	}
+	allErrs := append(v.validateName(nc), v.validateAccess(nc)...)
+	allErrs = append(allErrs, validatePodSecurity(nc)...)
+	allErrs = append(allErrs, validateNamespaceMetadata(nc)...)
+	allErrs = append(allErrs, validateOwners(nc)...)
+	if len(allErrs) == 0 {
+		collisions, err := v.validateCollisions(ctx, nc)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, collisions...)
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(namespaceconfigKind, nc.GetName(), allErrs)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.CustomValidator. Turning DeletionProtection
+// off takes an update of its own, which is recorded as an event.
+func (v *NamespaceconfigValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNC, err := asNamespaceconfig(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newNC, err := asNamespaceconfig(newObj)
+	if err != nil {
+		return nil, err
+	}
+	allErrs := field.ErrorList{}
+	if !equality.Semantic.DeepEqual(oldNC.Spec.Access, newNC.Spec.Access) {
+		allErrs = append(allErrs, v.validateAccess(newNC)...)
+	}
+	if !equality.Semantic.DeepEqual(oldNC.Spec.NamespaceLabels, newNC.Spec.NamespaceLabels) ||
+		!equality.Semantic.DeepEqual(oldNC.Spec.NamespaceAnnotations, newNC.Spec.NamespaceAnnotations) {
+		allErrs = append(allErrs, validateNamespaceMetadata(newNC)...)
+	}
+	if !equality.Semantic.DeepEqual(oldNC.Spec.Owners, newNC.Spec.Owners) {
+		allErrs = append(allErrs, validateOwners(newNC)...)
+	}
+	if newNC.GetDeletionTimestamp().IsZero() {
+		allErrs = append(allErrs, validatePodSecurity(newNC)...)
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(namespaceconfigKind, newNC.GetName(), allErrs)
+	}
+	if !config.DeletionProtected(oldNC, v.ProtectedEnvironments) || config.DeletionProtected(newNC, v.ProtectedEnvironments) {
+		return nil, nil
+	}
+	unprotected := oldNC.Spec.DeepCopy()
+	unprotected.DeletionProtection = newNC.Spec.DeletionProtection
+	if !equality.Semantic.DeepEqual(unprotected, &newNC.Spec) {
+		return nil, apierrors.NewForbidden(namespaceconfigResource, newNC.GetName(),
+			fmt.Errorf("spec.deletionProtection must be turned off in an update that changes nothing else in the spec"))
+	}
+	v.auditUnprotect(ctx, newNC)
+	return nil, nil
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *NamespaceconfigValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nc, err := asNamespaceconfig(obj)
+	if err != nil {
+		return nil, err
+	}
+	if config.DeletionProtected(nc, v.ProtectedEnvironments) {
+		return nil, apierrors.NewForbidden(namespaceconfigResource, nc.GetName(),
+			fmt.Errorf("the Namespaceconfig is protected against deletion, set spec.deletionProtection to false first"))
+	}
+	return nil, nil
+}
+
+// asNamespaceconfig returns obj as a Namespaceconfig or a bad request error
+func asNamespaceconfig(obj runtime.Object) (*namespaceconfigv2.Namespaceconfig, error) {
+	nc, ok := obj.(*namespaceconfigv2.Namespaceconfig)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Namespaceconfig but got a %T", obj))
+	}
+	return nc, nil
+}
+
+// auditUnprotect records who turned the DeletionProtection of a Namespaceconfig off
+func (v *NamespaceconfigValidator) auditUnprotect(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig) {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil || (req.DryRun != nil && *req.DryRun) {
+		return
+	}
+	util.Logs.Info("DeletionProtection of Namespaceconfig ", nc.GetName(), " turned off by ", req.UserInfo.Username)
+	if v.Recorder != nil {
+		v.Recorder.Eventf(nc, corev1.EventTypeWarning, "DeletionProtectionDisabled",
+			"DeletionProtection turned off by %s", req.UserInfo.Username)
+	}
+}
+
+// validateName checks the namespace name abbreviation-environment and the environment
+func (v *NamespaceconfigValidator) validateName(nc *namespaceconfigv2.Namespaceconfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	name := nc.Spec.Abbreviation + "-" + nc.Spec.Environment
+	if len(v.AllowedEnvironments) > 0 && !v.AllowedEnvironments.Has(nc.Spec.Environment) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("environment"), nc.Spec.Environment,
+			strings.Split(v.AllowedEnvironments.String(), ",")))
+	}
+	for _, msg := range validation.IsDNS1123Label(name) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("abbreviation"), nc.Spec.Abbreviation,
+			fmt.Sprintf("the namespace name %q made of abbreviation-environment is invalid: %s", name, msg)))
+	}
+	for _, prefix := range v.ReservedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("abbreviation"), nc.Spec.Abbreviation,
+				fmt.Sprintf("the namespace name %q starts with the reserved prefix %q, choose another abbreviation", name, prefix)))
+		}
+	}
+	return allErrs
+}
+
+// validateAccess checks that access only grants the allowed roles
+func (v *NamespaceconfigValidator) validateAccess(nc *namespaceconfigv2.Namespaceconfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, grant := range nc.Spec.Access {
+		role := grant.Role
+		if role == "" {
+			role = "view"
+		}
+		if !v.AccessRoles.Has(role) {
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "access").Index(i).Child("role"), role, v.AccessRoles))
+		}
+	}
+	return allErrs
+}
+
+// validatePodSecurity checks that an override of the Pod Security Standard of
+// the environment is annotated with the reason of the exemption
+func validatePodSecurity(nc *namespaceconfigv2.Namespaceconfig) field.ErrorList {
+	if nc.Spec.PodSecurity == "" || nc.GetAnnotations()[namespaceconfigv2.PodSecurityExemptionAnnotation] != "" {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "podSecurity"),
+		fmt.Sprintf("overriding the Pod Security Standard of the environment takes the annotation %s with the reason of the exemption",
+			namespaceconfigv2.PodSecurityExemptionAnnotation))}
+}
+
+// validateNamespaceMetadata checks the syntax of the custom labels and
+// annotations of the namespace and that they leave the reserved keys alone
+func validateNamespaceMetadata(nc *namespaceconfigv2.Namespaceconfig) field.ErrorList {
+	labelsPath := field.NewPath("spec", "namespaceLabels")
+	annotationsPath := field.NewPath("spec", "namespaceAnnotations")
+	allErrs := metav1validation.ValidateLabels(nc.Spec.NamespaceLabels, labelsPath)
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(nc.Spec.NamespaceAnnotations, annotationsPath)...)
+	for _, key := range sortedKeys(nc.Spec.NamespaceLabels) {
+		if util.ReservedKey(key) {
+			allErrs = append(allErrs, field.Forbidden(labelsPath.Key(key), "the label is reserved for the operator"))
+		}
+	}
+	for _, key := range sortedKeys(nc.Spec.NamespaceAnnotations) {
+		if util.ReservedKey(key) {
+			allErrs = append(allErrs, field.Forbidden(annotationsPath.Key(key), "the annotation is reserved for the operator"))
+		}
+	}
+	return allErrs
+}
+
+// validateOwners checks that the names and emails of the owners hold no
+// whitespace, like the schema of v2 does for the Namespaceconfigs written as v1
+func validateOwners(nc *namespaceconfigv2.Namespaceconfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, owner := range nc.Spec.Owners {
+		ownerPath := field.NewPath("spec", "owners").Index(i)
+		if strings.IndexFunc(owner.Name, unicode.IsSpace) >= 0 {
+			allErrs = append(allErrs, field.Invalid(ownerPath.Child("name"), owner.Name, "must not contain whitespace"))
+		}
+		if strings.IndexFunc(owner.Email, unicode.IsSpace) >= 0 {
+			allErrs = append(allErrs, field.Invalid(ownerPath.Child("email"), owner.Email, "must not contain whitespace"))
+		}
+	}
+	return allErrs
+}
+
+// sortedKeys returns the keys of m in order, so that errors are reported in a stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateCollisions checks that the namespace name is not taken by an existing
+// namespace or by another Namespaceconfig. Namespaces created by the operator
+// for a Namespaceconfig of the same name before claims existed can be adopted.
+func (v *NamespaceconfigValidator) validateCollisions(ctx context.Context, nc *namespaceconfigv2.Namespaceconfig) (field.ErrorList, error) {
+	allErrs := field.ErrorList{}
+	abbreviationPath := field.NewPath("spec", "abbreviation")
+	name := nc.Spec.Abbreviation + "-" + nc.Spec.Environment
+
+	ncs := &namespaceconfigv2.NamespaceconfigList{}
+	if err := v.Client.List(ctx, ncs); err != nil {
+		return nil, err
+	}
+	for i := range ncs.Items {
+		if other := &ncs.Items[i]; other.GetName() != nc.GetName() && namespaceName(other) == name {
+			allErrs = append(allErrs, field.Duplicate(abbreviationPath,
+				fmt.Sprintf("namespace %s is already managed by Namespaceconfig %s, choose another abbreviation", name, other.GetName())))
+		}
+	}
+
+	ns := &corev1.Namespace{}
+	err := v.Client.Get(ctx, client.ObjectKey{Name: name}, ns)
+	if apierrors.IsNotFound(err) {
+		return allErrs, nil
+	} else if err != nil {
+		return nil, err
+	}
+	annotations := ns.GetAnnotations()
+	if _, claimed := annotations[namespaceconfigv2.ClaimAnnotation]; claimed || annotations[namespaceconfigv2.NamespaceconfigAnnotation] != nc.GetName() {
+		allErrs = append(allErrs, field.Duplicate(abbreviationPath,
+			fmt.Sprintf("namespace %s already exists and is not managed by this Namespaceconfig, choose another abbreviation", name)))
+	}
+	return allErrs, nil
+}
@@ -0,0 +1,182 @@
+package v2
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+	"github.com/dguyhasnoname/ohmyk8s-operator/pkg/config"
+)
+
+var _ = Describe("Deletion protection", func() {
+	var (
+		ctx       context.Context
+		nc        *namespaceconfigv2.Namespaceconfig
+		protected config.Environments
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		protected = config.Environments{"prod": true}
+		nc = &namespaceconfigv2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: namespaceconfigv2.NamespaceconfigSpec{
+				Abbreviation: "pay",
+				Environment:  "prod",
+				Size:         "s",
+			},
+		}
+	})
+
+	It("rejects the deletion of a protected Namespaceconfig", func() {
+		validator := &NamespaceconfigValidator{ProtectedEnvironments: protected}
+		_, err := validator.ValidateDelete(ctx, nc)
+		Expect(err).To(HaveOccurred())
+
+		off := false
+		nc.Spec.DeletionProtection = &off
+		_, err = validator.ValidateDelete(ctx, nc)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("only turns protection off in an update of its own", func() {
+		validator := &NamespaceconfigValidator{ProtectedEnvironments: protected}
+		off := false
+		updated := nc.DeepCopy()
+		updated.Spec.DeletionProtection = &off
+		updated.Spec.Size = "m"
+		_, err := validator.ValidateUpdate(ctx, nc, updated)
+		Expect(err).To(HaveOccurred())
+
+		updated.Spec.Size = nc.Spec.Size
+		_, err = validator.ValidateUpdate(ctx, nc, updated)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects the deletion of the namespace of a protected Namespaceconfig", func() {
+		validator := &NamespaceValidator{
+			Client:                fake.NewClientBuilder().WithScheme(scheme).WithObjects(nc).Build(),
+			ProtectedEnvironments: protected,
+		}
+		deleteNamespace := func(name string) admission.Response {
+			return validator.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				Name:      name,
+			}})
+		}
+		Expect(deleteNamespace("pay-prod").Allowed).To(BeFalse())
+		Expect(deleteNamespace("other-prod").Allowed).To(BeTrue())
+	})
+})
+
+var _ = Describe("Namespaceconfig creation", func() {
+	var (
+		ctx       context.Context
+		nc        *namespaceconfigv2.Namespaceconfig
+		validator *NamespaceconfigValidator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		nc = &namespaceconfigv2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: namespaceconfigv2.NamespaceconfigSpec{
+				Abbreviation: "pay",
+				Environment:  "dev",
+				Size:         "s",
+			},
+		}
+		validator = &NamespaceconfigValidator{
+			Client:              fake.NewClientBuilder().WithScheme(scheme).Build(),
+			AllowedEnvironments: config.Environments{"dev": true, "prod": true},
+			ReservedPrefixes:    config.List{"kube-", "openshift-"},
+			AccessRoles:         config.List{"admin", "edit", "view"},
+		}
+	})
+
+	It("rejects objects of another type instead of panicking", func() {
+		_, err := validator.ValidateCreate(ctx, &corev1.Namespace{})
+		Expect(err).To(MatchError(ContainSubstring("expected a Namespaceconfig")))
+	})
+
+	It("accepts a free namespace name", func() {
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("rejects invalid names",
+		func(abbreviation, environment, message string) {
+			nc.Spec.Abbreviation, nc.Spec.Environment = abbreviation, environment
+			_, err := validator.ValidateCreate(ctx, nc)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("not a DNS-1123 label", "Pay_1", "dev", "spec.abbreviation"),
+		Entry("reserved prefix", "kube", "dev", `reserved prefix "kube-"`),
+		Entry("unknown environment", "pay", "qa", "spec.environment: Unsupported value"),
+	)
+
+	It("rejects access to a role that is not allowed", func() {
+		nc.Spec.Access = []namespaceconfigv2.AccessGrant{
+			{Kind: namespaceconfigv2.OwnerGroup, Name: "auditors"},
+			{Kind: namespaceconfigv2.OwnerServiceAccount, Name: "deployer", Namespace: "ci", Role: "cluster-admin"},
+		}
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(ContainSubstring(`spec.access[1].role: Unsupported value: "cluster-admin"`)))
+	})
+
+	It("rejects owners with whitespace, which could add YAML to NamespaceTemplates", func() {
+		nc.Spec.Owners = []namespaceconfigv2.Owner{
+			{Kind: namespaceconfigv2.OwnerUser, Name: "alice", Email: "alice@example.com"},
+			{Kind: namespaceconfigv2.OwnerUser, Name: "bob\n---\nkind: RoleBinding"},
+		}
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(ContainSubstring("spec.owners[1].name: Invalid value")))
+	})
+
+	It("only overrides the Pod Security Standard with an exemption", func() {
+		nc.Spec.PodSecurity = namespaceconfigv2.PodSecurityPrivileged
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(ContainSubstring("spec.podSecurity: Forbidden")))
+
+		nc.SetAnnotations(map[string]string{namespaceconfigv2.PodSecurityExemptionAnnotation: "runs the CNI agents"})
+		_, err = validator.ValidateCreate(ctx, nc)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("keeps custom labels and annotations off the reserved keys", func() {
+		nc.Spec.NamespaceLabels = map[string]string{"cost-center": "4711", "istio-injection": "enabled"}
+		nc.Spec.NamespaceAnnotations = map[string]string{"example.com/team-channel": "#payments"}
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).NotTo(HaveOccurred())
+
+		nc.Spec.NamespaceLabels["env"] = "prod"
+		nc.Spec.NamespaceAnnotations["pod-security.kubernetes.io/enforce"] = "privileged"
+		_, err = validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(And(
+			ContainSubstring("spec.namespaceLabels[env]: Forbidden"),
+			ContainSubstring("spec.namespaceAnnotations[pod-security.kubernetes.io/enforce]: Forbidden"))))
+	})
+
+	It("rejects a name taken by another Namespaceconfig", func() {
+		other := nc.DeepCopy()
+		other.SetName("payments-old")
+		validator.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(other).Build()
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(ContainSubstring("already managed by Namespaceconfig payments-old")))
+	})
+
+	It("rejects a name taken by a foreign namespace", func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-dev"}}
+		validator.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
+		_, err := validator.ValidateCreate(ctx, nc)
+		Expect(err).To(MatchError(ContainSubstring("namespace pay-dev already exists")))
+	})
+})
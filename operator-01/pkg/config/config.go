@@ -0,0 +1,305 @@
+// Package config holds the cluster wide settings of the operator that are set
+// with command line flags of the manager.
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// defaultEnvironment is the key setting the policy of environments without their own entry
+const defaultEnvironment = "*"
+
+// DeletionPolicies holds the default DeletionPolicy of each environment. It
+// implements flag.Value and is set with a comma separated list of
+// environment=policy pairs, where the environment * matches any environment:
+//
+//	--deletion-policy=prod=Retain,*=Delete
+type DeletionPolicies map[string]namespaceconfigv2.DeletionPolicy
+
+// For returns the default DeletionPolicy of an environment
+func (d DeletionPolicies) For(environment string) namespaceconfigv2.DeletionPolicy {
+	if policy, ok := d[environment]; ok {
+		return policy
+	}
+	if policy, ok := d[defaultEnvironment]; ok {
+		return policy
+	}
+	return namespaceconfigv2.DeletionPolicyDelete
+}
+
+// String returns the policies in the flag format
+func (d DeletionPolicies) String() string {
+	pairs := []string{}
+	for environment, policy := range d {
+		pairs = append(pairs, environment+"="+string(policy))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the environment=policy pairs of value
+func (d DeletionPolicies) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		environment, policy, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || environment == "" {
+			return fmt.Errorf("invalid deletion policy %q, expected environment=policy", pair)
+		}
+		switch p := namespaceconfigv2.DeletionPolicy(policy); p {
+		case namespaceconfigv2.DeletionPolicyDelete, namespaceconfigv2.DeletionPolicyRetain, namespaceconfigv2.DeletionPolicyOrphan:
+			d[environment] = p
+		default:
+			return fmt.Errorf("invalid deletion policy %q for environment %s, expected Delete, Retain or Orphan", policy, environment)
+		}
+	}
+	return nil
+}
+
+// GroupResources is a list of resources like statefulsets.apps. It implements
+// flag.Value and is set with a comma separated list of resource.group names.
+type GroupResources []schema.GroupResource
+
+// String returns the resources in the flag format
+func (g *GroupResources) String() string {
+	names := []string{}
+	for _, gr := range *g {
+		names = append(names, gr.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Set adds the resources of value
+func (g *GroupResources) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("invalid resource list %q", value)
+		}
+		*g = append(*g, schema.ParseGroupResource(name))
+	}
+	return nil
+}
+
+// Environments is a set of environments. It implements flag.Value and is set
+// with a comma separated list of environments.
+type Environments map[string]bool
+
+// Has reports whether environment is in the set
+func (e Environments) Has(environment string) bool {
+	return e[environment]
+}
+
+// String returns the environments in the flag format
+func (e Environments) String() string {
+	names := []string{}
+	for environment := range e {
+		names = append(names, environment)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// Set adds the environments of value
+func (e Environments) Set(value string) error {
+	for _, environment := range strings.Split(value, ",") {
+		environment = strings.TrimSpace(environment)
+		if environment == "" {
+			return fmt.Errorf("invalid environment list %q", value)
+		}
+		e[environment] = true
+	}
+	return nil
+}
+
+// DeletionProtected reports whether a Namespaceconfig is protected against
+// deletion, by its spec or else because its environment is in protected
+func DeletionProtected(nc *namespaceconfigv2.Namespaceconfig, protected Environments) bool {
+	if nc.Spec.DeletionProtection != nil {
+		return *nc.Spec.DeletionProtection
+	}
+	return protected.Has(nc.Spec.Environment)
+}
+
+// ClaimedName is the Namespaceconfig of a claim: the one it names, or the one
+// provisioned for it named after its namespace and name
+func ClaimedName(claim *namespaceconfigv2.NamespaceClaim) string {
+	if claim.Spec.NamespaceconfigName != "" {
+		return claim.Spec.NamespaceconfigName
+	}
+	return claim.GetNamespace() + "-" + claim.GetName()
+}
+
+// List is a list of strings. It implements flag.Value and is set with a comma
+// separated list.
+type List []string
+
+// String returns the list in the flag format
+func (l *List) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Has reports whether item is in the list
+func (l List) Has(item string) bool {
+	for _, i := range l {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Set replaces the list with the items of value
+func (l *List) Set(value string) error {
+	items := List{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*l = items
+	return nil
+}
+
+// PerEnvironment holds a value per environment. It implements flag.Value and
+// is set with a comma separated list of environment=value pairs, where the
+// environment * matches any environment.
+type PerEnvironment map[string]string
+
+// For returns the value of an environment
+func (p PerEnvironment) For(environment string) (string, bool) {
+	if value, ok := p[environment]; ok {
+		return value, true
+	}
+	value, ok := p[defaultEnvironment]
+	return value, ok
+}
+
+// String returns the values in the flag format
+func (p PerEnvironment) String() string {
+	pairs := []string{}
+	for environment, value := range p {
+		pairs = append(pairs, environment+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the environment=value pairs of value
+func (p PerEnvironment) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		environment, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || environment == "" || v == "" {
+			return fmt.Errorf("invalid value %q, expected environment=value", pair)
+		}
+		p[environment] = v
+	}
+	return nil
+}
+
+// Bundles of NetworkPolicies applied to the namespaces of an environment
+const (
+	// NetworkPoliciesNone applies no NetworkPolicy
+	NetworkPoliciesNone = "none"
+	// NetworkPoliciesBaseline denies ingress except from the namespace itself and
+	// from the ingress controller, egress is left open
+	NetworkPoliciesBaseline = "baseline"
+	// NetworkPoliciesStrict denies ingress and egress except within the
+	// namespace, DNS and ingress from the ingress controller
+	NetworkPoliciesStrict = "strict"
+)
+
+// NetworkPolicyBundles holds the NetworkPolicy bundle of each environment. It
+// implements flag.Value and is set with a comma separated list of
+// environment=bundle pairs, where the environment * matches any environment:
+//
+//	--network-policies=prod=strict,*=baseline
+type NetworkPolicyBundles map[string]string
+
+// For returns the bundle of an environment, baseline when none is set
+func (n NetworkPolicyBundles) For(environment string) string {
+	if bundle, ok := n[environment]; ok {
+		return bundle
+	}
+	if bundle, ok := n[defaultEnvironment]; ok {
+		return bundle
+	}
+	return NetworkPoliciesBaseline
+}
+
+// String returns the bundles in the flag format
+func (n NetworkPolicyBundles) String() string {
+	pairs := []string{}
+	for environment, bundle := range n {
+		pairs = append(pairs, environment+"="+bundle)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the environment=bundle pairs of value
+func (n NetworkPolicyBundles) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		environment, bundle, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || environment == "" {
+			return fmt.Errorf("invalid network policy bundle %q, expected environment=bundle", pair)
+		}
+		switch bundle {
+		case NetworkPoliciesNone, NetworkPoliciesBaseline, NetworkPoliciesStrict:
+			n[environment] = bundle
+		default:
+			return fmt.Errorf("invalid network policy bundle %q for environment %s, expected none, baseline or strict", bundle, environment)
+		}
+	}
+	return nil
+}
+
+// PodSecurityLevels holds the Pod Security Standard enforced in the namespaces
+// of each environment. It implements flag.Value and is set with a comma
+// separated list of environment=level pairs, where the environment * matches
+// any environment:
+//
+//	--pod-security=prod=restricted,*=baseline
+type PodSecurityLevels map[string]namespaceconfigv2.PodSecurityLevel
+
+// For returns the level of an environment, baseline when none is set
+func (p PodSecurityLevels) For(environment string) namespaceconfigv2.PodSecurityLevel {
+	if level, ok := p[environment]; ok {
+		return level
+	}
+	if level, ok := p[defaultEnvironment]; ok {
+		return level
+	}
+	return namespaceconfigv2.PodSecurityBaseline
+}
+
+// String returns the levels in the flag format
+func (p PodSecurityLevels) String() string {
+	pairs := []string{}
+	for environment, level := range p {
+		pairs = append(pairs, environment+"="+string(level))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the environment=level pairs of value
+func (p PodSecurityLevels) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		environment, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || environment == "" {
+			return fmt.Errorf("invalid pod security level %q, expected environment=level", pair)
+		}
+		switch l := namespaceconfigv2.PodSecurityLevel(level); l {
+		case namespaceconfigv2.PodSecurityPrivileged, namespaceconfigv2.PodSecurityBaseline, namespaceconfigv2.PodSecurityRestricted:
+			p[environment] = l
+		default:
+			return fmt.Errorf("invalid pod security level %q for environment %s, expected privileged, baseline or restricted", level, environment)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("DeletionPolicies", func() {
+	It("defaults to Delete", func() {
+		Expect(DeletionPolicies{}.For("prod")).To(Equal(namespaceconfigv2.DeletionPolicyDelete))
+	})
+
+	It("prefers the policy of the environment over the wildcard", func() {
+		policies := DeletionPolicies{}
+		Expect(policies.Set("prod=Retain, *=Orphan")).To(Succeed())
+		Expect(policies.For("prod")).To(Equal(namespaceconfigv2.DeletionPolicyRetain))
+		Expect(policies.For("dev")).To(Equal(namespaceconfigv2.DeletionPolicyOrphan))
+		Expect(policies.String()).To(Equal("*=Orphan,prod=Retain"))
+	})
+
+	It("rejects unknown policies and malformed pairs", func() {
+		Expect(DeletionPolicies{}.Set("prod=Keep")).NotTo(Succeed())
+		Expect(DeletionPolicies{}.Set("Retain")).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("GroupResources", func() {
+	It("parses core and grouped resources", func() {
+		resources := GroupResources{}
+		Expect(resources.Set("configmaps, statefulsets.apps")).To(Succeed())
+		Expect(resources).To(Equal(GroupResources{
+			{Resource: "configmaps"},
+			{Group: "apps", Resource: "statefulsets"},
+		}))
+		Expect(resources.String()).To(Equal("configmaps,statefulsets.apps"))
+	})
+})
+
+var _ = Describe("DeletionProtected", func() {
+	protected := Environments{"prod": true}
+
+	It("follows the environment when the spec does not say", func() {
+		nc := &namespaceconfigv2.Namespaceconfig{Spec: namespaceconfigv2.NamespaceconfigSpec{Environment: "prod"}}
+		Expect(DeletionProtected(nc, protected)).To(BeTrue())
+		nc.Spec.Environment = "dev"
+		Expect(DeletionProtected(nc, protected)).To(BeFalse())
+	})
+
+	It("prefers the spec over the environment", func() {
+		off := false
+		nc := &namespaceconfigv2.Namespaceconfig{Spec: namespaceconfigv2.NamespaceconfigSpec{Environment: "prod", DeletionProtection: &off}}
+		Expect(DeletionProtected(nc, protected)).To(BeFalse())
+	})
+})
+
+var _ = Describe("ClaimedName", func() {
+	It("names a provisioned Namespaceconfig after the claim", func() {
+		claim := &namespaceconfigv2.NamespaceClaim{ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "team-billing"}}
+		Expect(ClaimedName(claim)).To(Equal("team-billing-billing"))
+		claim.Spec.NamespaceconfigName = "billing"
+		Expect(ClaimedName(claim)).To(Equal("billing"))
+	})
+})
+
+var _ = Describe("PerEnvironment", func() {
+	It("falls back to the wildcard", func() {
+		sizes := PerEnvironment{}
+		Expect(sizes.Set("prod=m,*=s")).To(Succeed())
+		size, ok := sizes.For("prod")
+		Expect(ok).To(BeTrue())
+		Expect(size).To(Equal("m"))
+		size, _ = sizes.For("dev")
+		Expect(size).To(Equal("s"))
+		_, ok = PerEnvironment{}.For("dev")
+		Expect(ok).To(BeFalse())
+		Expect(sizes.Set("prod=")).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("NetworkPolicyBundles", func() {
+	It("defaults to baseline", func() {
+		Expect(NetworkPolicyBundles{}.For("dev")).To(Equal(NetworkPoliciesBaseline))
+	})
+
+	It("prefers the bundle of the environment over the wildcard", func() {
+		bundles := NetworkPolicyBundles{}
+		Expect(bundles.Set("prod=strict,*=none")).To(Succeed())
+		Expect(bundles.For("prod")).To(Equal(NetworkPoliciesStrict))
+		Expect(bundles.For("dev")).To(Equal(NetworkPoliciesNone))
+	})
+
+	It("rejects unknown bundles", func() {
+		Expect(NetworkPolicyBundles{}.Set("prod=open")).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("PodSecurityLevels", func() {
+	It("defaults to baseline", func() {
+		Expect(PodSecurityLevels{}.For("dev")).To(Equal(namespaceconfigv2.PodSecurityBaseline))
+	})
+
+	It("rejects unknown levels", func() {
+		levels := PodSecurityLevels{}
+		Expect(levels.Set("prod=restricted")).To(Succeed())
+		Expect(levels.For("prod")).To(Equal(namespaceconfigv2.PodSecurityRestricted))
+		Expect(levels.Set("dev=open")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,96 @@
+// Package replication decides which Secrets and ConfigMaps of the replication
+// namespace are copied into a namespace, which objects of the namespace a copy
+// must not replace, which copies are left over and which Namespaceconfigs hold
+// copies of a source.
+package replication
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// Replicates reports whether a source is copied into the namespaces of
+// environment and size. A missing or empty selector matches everything.
+func Replicates(source metav1.Object, environment, size string) bool {
+	annotations := source.GetAnnotations()
+	if annotations[namespaceconfigv2.ReplicateAnnotation] != "true" {
+		return false
+	}
+	return listed(annotations[namespaceconfigv2.ReplicateEnvironmentsAnnotation], environment) &&
+		listed(annotations[namespaceconfigv2.ReplicateSizesAnnotation], size)
+}
+
+// listed reports whether value is in the comma separated list, ignoring case.
+// Everything is in an empty list.
+func listed(list, value string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta is the metadata of the copy of source in namespaceName
+func Meta(source metav1.Object, namespaceName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        source.GetName(),
+		Namespace:   namespaceName,
+		Labels:      map[string]string{namespaceconfigv2.ReplicatedLabel: "true"},
+		Annotations: map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: source.GetNamespace() + "/" + source.GetName()},
+	}
+}
+
+// IsCopy reports whether obj is a copy controlled by owner
+func IsCopy(obj, owner metav1.Object) bool {
+	_, ok := obj.GetAnnotations()[namespaceconfigv2.ReplicatedFromAnnotation]
+	return ok && metav1.IsControlledBy(obj, owner)
+}
+
+// Conflicts reports whether live, the object of the namespace with the name of
+// the copy of source, must be left alone: it is not a copy controlled by owner,
+// or it is the copy of another source
+func Conflicts(live, owner metav1.Object, source string) bool {
+	return !IsCopy(live, owner) || live.GetAnnotations()[namespaceconfigv2.ReplicatedFromAnnotation] != source
+}
+
+// Stale returns the keys of the copies that are not desired, in order
+func Stale(copies map[string]client.Object, desired map[string]bool) []string {
+	stale := []string{}
+	for key := range copies {
+		if !desired[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// Holders returns the names of the Namespaceconfigs controlling the copies of
+// source, the namespace/name of a source, among copies, in order
+func Holders(copies []metav1.Object, source string) []string {
+	names := map[string]bool{}
+	for _, obj := range copies {
+		if obj.GetAnnotations()[namespaceconfigv2.ReplicatedFromAnnotation] != source {
+			continue
+		}
+		owner := metav1.GetControllerOf(obj)
+		if owner != nil && owner.Kind == "Namespaceconfig" && strings.HasPrefix(owner.APIVersion, namespaceconfigv2.GroupVersion.Group+"/") {
+			names[owner.Name] = true
+		}
+	}
+	holders := []string{}
+	for name := range names {
+		holders = append(holders, name)
+	}
+	sort.Strings(holders)
+	return holders
+}
@@ -0,0 +1,115 @@
+package replication
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("Replication", func() {
+	owner := &namespaceconfigv2.Namespaceconfig{ObjectMeta: metav1.ObjectMeta{Name: "payments", UID: types.UID("payments-uid")}}
+	controlledBy := func(uid types.UID) []metav1.OwnerReference {
+		controller := true
+		return []metav1.OwnerReference{{APIVersion: namespaceconfigv2.GroupVersion.String(), Kind: "Namespaceconfig", Name: "payments", UID: uid, Controller: &controller}}
+	}
+
+	DescribeTable("selects the sources of a namespace",
+		func(annotations map[string]string, expected bool) {
+			source := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "shared", Annotations: annotations}}
+			Expect(Replicates(source, "prod", "m")).To(Equal(expected))
+		},
+		Entry("not marked", map[string]string{}, false),
+		Entry("marked false", map[string]string{namespaceconfigv2.ReplicateAnnotation: "false"}, false),
+		Entry("without selectors", map[string]string{namespaceconfigv2.ReplicateAnnotation: "true"}, true),
+		Entry("with empty selectors", map[string]string{
+			namespaceconfigv2.ReplicateAnnotation:             "true",
+			namespaceconfigv2.ReplicateEnvironmentsAnnotation: " ",
+			namespaceconfigv2.ReplicateSizesAnnotation:        "",
+		}, true),
+		Entry("with the environment listed in another case", map[string]string{
+			namespaceconfigv2.ReplicateAnnotation:             "true",
+			namespaceconfigv2.ReplicateEnvironmentsAnnotation: "dev, PROD",
+		}, true),
+		Entry("with another environment", map[string]string{
+			namespaceconfigv2.ReplicateAnnotation:             "true",
+			namespaceconfigv2.ReplicateEnvironmentsAnnotation: "dev,stg",
+		}, false),
+		Entry("with the size listed", map[string]string{
+			namespaceconfigv2.ReplicateAnnotation:      "true",
+			namespaceconfigv2.ReplicateSizesAnnotation: "s,m",
+		}, true),
+		Entry("with another size", map[string]string{
+			namespaceconfigv2.ReplicateAnnotation:             "true",
+			namespaceconfigv2.ReplicateEnvironmentsAnnotation: "prod",
+			namespaceconfigv2.ReplicateSizesAnnotation:        "l",
+		}, false),
+	)
+
+	It("labels and annotates copies", func() {
+		source := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "shared"}}
+		copyMeta := Meta(source, "pay-prod")
+		Expect(copyMeta.Name).To(Equal("ca"))
+		Expect(copyMeta.Namespace).To(Equal("pay-prod"))
+		Expect(copyMeta.Labels).To(HaveKeyWithValue(namespaceconfigv2.ReplicatedLabel, "true"))
+		Expect(copyMeta.Annotations).To(HaveKeyWithValue(namespaceconfigv2.ReplicatedFromAnnotation, "shared/ca"))
+	})
+
+	DescribeTable("finds conflicts with the objects of the namespace",
+		func(annotations map[string]string, owners []metav1.OwnerReference, isCopy, conflicts bool) {
+			live := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "pull", Namespace: "pay-prod", Annotations: annotations, OwnerReferences: owners,
+			}}
+			Expect(IsCopy(live, owner)).To(Equal(isCopy))
+			Expect(Conflicts(live, owner, "shared/pull")).To(Equal(conflicts))
+		},
+		Entry("the copy of the source", map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: "shared/pull"},
+			controlledBy(owner.GetUID()), true, false),
+		Entry("the copy of another source", map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: "other/pull"},
+			controlledBy(owner.GetUID()), true, true),
+		Entry("a copy of another Namespaceconfig", map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: "shared/pull"},
+			controlledBy(types.UID("other-uid")), false, true),
+		Entry("an object of the namespace", nil, nil, false, true),
+		Entry("a controlled object that is no copy", nil, controlledBy(owner.GetUID()), false, true),
+	)
+
+	It("returns the copies that are not desired, in order", func() {
+		copies := map[string]client.Object{
+			"Secret/pull":    &corev1.Secret{},
+			"Secret/old":     &corev1.Secret{},
+			"ConfigMap/ca":   &corev1.ConfigMap{},
+			"ConfigMap/gone": &corev1.ConfigMap{},
+		}
+		Expect(Stale(copies, map[string]bool{"Secret/pull": true, "ConfigMap/ca": true})).To(Equal([]string{"ConfigMap/gone", "Secret/old"}))
+		Expect(Stale(copies, map[string]bool{})).To(HaveLen(4))
+		Expect(Stale(map[string]client.Object{}, map[string]bool{"Secret/pull": true})).To(BeEmpty())
+	})
+
+	It("returns the Namespaceconfigs holding copies of a source", func() {
+		copyOf := func(source string, owners []metav1.OwnerReference) metav1.Object {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{namespaceconfigv2.ReplicatedFromAnnotation: source},
+				OwnerReferences: owners,
+			}}
+		}
+		billing := controlledBy(types.UID("billing-uid"))
+		billing[0].Name = "billing"
+		foreign := controlledBy(owner.GetUID())
+		foreign[0].APIVersion = "apps/v1"
+		foreign[0].Kind = "Deployment"
+		copies := []metav1.Object{
+			copyOf("shared/pull", controlledBy(owner.GetUID())),
+			copyOf("shared/pull", billing),
+			copyOf("shared/pull", controlledBy(owner.GetUID())),
+			copyOf("shared/ca", controlledBy(types.UID("other-uid"))),
+			copyOf("shared/pull", foreign),
+			copyOf("shared/pull", nil),
+		}
+		Expect(Holders(copies, "shared/pull")).To(Equal([]string{"billing", "payments"}))
+		Expect(Holders(copies, "shared/gone")).To(BeEmpty())
+	})
+})
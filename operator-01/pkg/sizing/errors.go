@@ -0,0 +1,43 @@
+package sizing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// ErrNoSize is returned for a Namespaceconfig without size
+var ErrNoSize = errors.New("spec.size is not set")
+
+// InvalidError reports a size that cannot be applied: a malformed size name,
+// or a profile whose effective LimitRange and ResourceQuota are invalid
+type InvalidError struct {
+	Size string
+	Errs field.ErrorList
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf("size %q is invalid: %s", e.Size, e.Errs.ToAggregate().Error())
+}
+
+// ProfileName returns the name of the NamespaceSizeProfile of a Namespaceconfig.
+// Sizes are matched case-insensitively against profile names.
+func ProfileName(nc *namespaceconfigv2.Namespaceconfig) (string, error) {
+	if nc.Spec.Size == "" {
+		return "", ErrNoSize
+	}
+	size := strings.ToLower(nc.Spec.Size)
+	errs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Label(size) {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "size"), nc.Spec.Size, msg))
+	}
+	if len(errs) > 0 {
+		return "", &InvalidError{Size: nc.Spec.Size, Errs: errs}
+	}
+	return size, nil
+}
@@ -0,0 +1,40 @@
+package sizing
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("ProfileName", func() {
+	profileName := func(size string) (string, error) {
+		return ProfileName(&namespaceconfigv2.Namespaceconfig{Spec: namespaceconfigv2.NamespaceconfigSpec{Size: size}})
+	}
+
+	It("matches sizes case-insensitively", func() {
+		Expect(profileName("S")).To(Equal("s"))
+		Expect(profileName("xl-gpu")).To(Equal("xl-gpu"))
+	})
+
+	It("reports a missing size", func() {
+		_, err := profileName("")
+		Expect(errors.Is(err, ErrNoSize)).To(BeTrue())
+	})
+
+	DescribeTable("reports malformed sizes",
+		func(size string) {
+			_, err := profileName(size)
+			var invalid *InvalidError
+			Expect(errors.As(err, &invalid)).To(BeTrue())
+			Expect(invalid.Errs).NotTo(BeEmpty())
+			Expect(invalid.Errs[0].Field).To(Equal("spec.size"))
+		},
+		Entry("underscore", "x_l"),
+		Entry("leading dash", "-s"),
+		Entry("too long", strings.Repeat("s", 64)),
+	)
+})
@@ -0,0 +1,80 @@
+package sizing
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// Effective merges the inline overrides of a Namespaceconfig on top of its size
+// profile and validates the result. The returned errors point into the
+// effectiveLimits and effectiveQuota status fields.
+func Effective(nc *namespaceconfigv2.Namespaceconfig, profile *namespaceconfigv1.NamespaceSizeProfile) (corev1.LimitRangeSpec, corev1.ResourceQuotaSpec, field.ErrorList) {
+	limits := MergeLimits(profile.Spec.LimitRange, nc.Spec.Limits)
+	quota := MergeQuota(profile.Spec.ResourceQuota, nc.Spec.Quota)
+	statusPath := field.NewPath("status")
+	errs := Validate(limits, quota, statusPath.Child("effectiveLimits"), statusPath.Child("effectiveQuota"))
+	return limits, quota, errs
+}
+
+// MergeLimits returns a copy of base with override merged on top. Items are
+// matched by type: resources set in an override item replace the same
+// resources of the base item, and items of a type missing in base are appended.
+func MergeLimits(base corev1.LimitRangeSpec, override *corev1.LimitRangeSpec) corev1.LimitRangeSpec {
+	merged := *base.DeepCopy()
+	if override == nil {
+		return merged
+	}
+	for _, item := range override.Limits {
+		found := false
+		for i := range merged.Limits {
+			if merged.Limits[i].Type != item.Type {
+				continue
+			}
+			target := &merged.Limits[i]
+			target.Max = mergeResources(target.Max, item.Max)
+			target.Min = mergeResources(target.Min, item.Min)
+			target.Default = mergeResources(target.Default, item.Default)
+			target.DefaultRequest = mergeResources(target.DefaultRequest, item.DefaultRequest)
+			target.MaxLimitRequestRatio = mergeResources(target.MaxLimitRequestRatio, item.MaxLimitRequestRatio)
+			found = true
+		}
+		if !found {
+			merged.Limits = append(merged.Limits, *item.DeepCopy())
+		}
+	}
+	return merged
+}
+
+// MergeQuota returns a copy of base with override merged on top. Hard limits
+// are merged per resource, scopes and the scope selector replace the ones of
+// base when set.
+func MergeQuota(base corev1.ResourceQuotaSpec, override *corev1.ResourceQuotaSpec) corev1.ResourceQuotaSpec {
+	merged := *base.DeepCopy()
+	if override == nil {
+		return merged
+	}
+	merged.Hard = mergeResources(merged.Hard, override.Hard)
+	if len(override.Scopes) > 0 {
+		merged.Scopes = append([]corev1.ResourceQuotaScope{}, override.Scopes...)
+	}
+	if override.ScopeSelector != nil {
+		merged.ScopeSelector = override.ScopeSelector.DeepCopy()
+	}
+	return merged
+}
+
+func mergeResources(base, override corev1.ResourceList) corev1.ResourceList {
+	if len(override) == 0 {
+		return base
+	}
+	if base == nil {
+		base = corev1.ResourceList{}
+	}
+	for name, quantity := range override {
+		base[name] = quantity.DeepCopy()
+	}
+	return base
+}
@@ -0,0 +1,90 @@
+package sizing
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("Effective", func() {
+	var (
+		profile *namespaceconfigv1.NamespaceSizeProfile
+		nc      *namespaceconfigv2.Namespaceconfig
+	)
+
+	BeforeEach(func() {
+		profile = &namespaceconfigv1.NamespaceSizeProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "s"},
+			Spec: namespaceconfigv1.NamespaceSizeProfileSpec{
+				LimitRange: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+					{
+						Type:           corev1.LimitTypeContainer,
+						Max:            quantities(map[corev1.ResourceName]string{"cpu": "1", "memory": "1Gi"}),
+						Default:        quantities(map[corev1.ResourceName]string{"cpu": "500m", "memory": "512Mi"}),
+						DefaultRequest: quantities(map[corev1.ResourceName]string{"cpu": "100m", "memory": "256Mi"}),
+					},
+					{
+						Type: corev1.LimitTypePersistentVolumeClaim,
+						Max:  quantities(map[corev1.ResourceName]string{"storage": "100Gi"}),
+					},
+				}},
+				ResourceQuota: corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{
+					"requests.cpu": "8", "limits.cpu": "16", "requests.storage": "500Gi", "pods": "200",
+				})},
+			},
+		}
+		nc = &namespaceconfigv2.Namespaceconfig{ObjectMeta: metav1.ObjectMeta{Name: "apr"}}
+	})
+
+	It("returns the profile when there are no overrides", func() {
+		limits, quota, errs := Effective(nc, profile)
+		Expect(errs).To(BeEmpty())
+		Expect(limits).To(Equal(profile.Spec.LimitRange))
+		Expect(quota).To(Equal(profile.Spec.ResourceQuota))
+	})
+
+	It("overrides only the resources set inline", func() {
+		nc.Spec.Limits = &corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Max:  quantities(map[corev1.ResourceName]string{"storage": "250Gi"}),
+		}}}
+		nc.Spec.Quota = &corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"pods": "400"})}
+		limits, quota, errs := Effective(nc, profile)
+		Expect(errs).To(BeEmpty())
+		Expect(limits.Limits).To(HaveLen(2))
+		Expect(limits.Limits[0]).To(Equal(profile.Spec.LimitRange.Limits[0]))
+		Expect(limits.Limits[1].Max.Storage().Equal(resource.MustParse("250Gi"))).To(BeTrue())
+		Expect(quota.Hard.Pods().Equal(resource.MustParse("400"))).To(BeTrue())
+		Expect(quota.Hard).To(HaveKey(corev1.ResourceName("requests.cpu")))
+		// the profile itself is left untouched
+		Expect(profile.Spec.LimitRange.Limits[1].Max.Storage().Equal(resource.MustParse("100Gi"))).To(BeTrue())
+		Expect(profile.Spec.ResourceQuota.Hard.Pods().Equal(resource.MustParse("200"))).To(BeTrue())
+	})
+
+	It("appends items of a type the profile does not limit", func() {
+		nc.Spec.Limits = &corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePod,
+			Max:  quantities(map[corev1.ResourceName]string{"cpu": "2"}),
+		}}}
+		limits, _, errs := Effective(nc, profile)
+		Expect(errs).To(BeEmpty())
+		Expect(limits.Limits).To(HaveLen(3))
+		Expect(limits.Limits[2].Type).To(Equal(corev1.LimitTypePod))
+	})
+
+	It("rejects overrides that contradict the merged quota", func() {
+		nc.Spec.Limits = &corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Max:  quantities(map[corev1.ResourceName]string{"storage": "1Ti"}),
+		}}}
+		_, _, errs := Effective(nc, profile)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("status.effectiveLimits.limits[1].max[storage]"))
+	})
+})
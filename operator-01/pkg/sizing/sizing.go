@@ -0,0 +1,155 @@
+// Package sizing holds the logic around NamespaceSizeProfiles that does not
+// need a cluster: merging the overrides of a Namespaceconfig into its size and
+// consistency checks between the resulting LimitRange and ResourceQuota.
+package sizing
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+)
+
+// computeResources are the resources constrained by both a LimitRange and a ResourceQuota
+var computeResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// ValidateProfile checks that the LimitRange and ResourceQuota of a
+// NamespaceSizeProfile do not contradict each other
+func ValidateProfile(profile *namespaceconfigv1.NamespaceSizeProfile) field.ErrorList {
+	specPath := field.NewPath("spec")
+	return Validate(profile.Spec.LimitRange, profile.Spec.ResourceQuota, specPath.Child("LimitRange"), specPath.Child("ResourceQuota"))
+}
+
+// Validate checks that a LimitRange and a ResourceQuota hold no negative
+// quantities and do not contradict each other: anything the LimitRange admits or defaults for a single pod, container
+// or PVC has to fit into the quota, and a quota on compute resources needs
+// LimitRange defaults, otherwise pods without resources are always rejected.
+// limitsPath and quotaPath are used to build the paths of the returned errors.
+func Validate(limits corev1.LimitRangeSpec, quota corev1.ResourceQuotaSpec, limitsPath, quotaPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	hardPath := quotaPath.Child("hard")
+	allErrs = append(allErrs, notNegative(quota.Hard, hardPath)...)
+	var container *corev1.LimitRangeItem
+	for i := range limits.Limits {
+		item := &limits.Limits[i]
+		itemPath := limitsPath.Child("limits").Index(i)
+		allErrs = append(allErrs, notNegative(item.Max, itemPath.Child("max"))...)
+		allErrs = append(allErrs, notNegative(item.Min, itemPath.Child("min"))...)
+		allErrs = append(allErrs, notNegative(item.Default, itemPath.Child("default"))...)
+		allErrs = append(allErrs, notNegative(item.DefaultRequest, itemPath.Child("defaultRequest"))...)
+		switch item.Type {
+		case corev1.LimitTypeContainer, corev1.LimitTypePod:
+			if item.Type == corev1.LimitTypeContainer {
+				container = item
+			}
+			for _, name := range computeResources {
+				allErrs = append(allErrs, validateItemOrder(item, name, itemPath)...)
+				if limit, ok := quotaLimit(quota, name); ok {
+					allErrs = append(allErrs, notAbove(item.Max, name, itemPath.Child("max"), limit, hardPath, "limits."+string(name))...)
+					allErrs = append(allErrs, notAbove(item.Default, name, itemPath.Child("default"), limit, hardPath, "limits."+string(name))...)
+				}
+				if request, ok := quotaRequest(quota, name); ok {
+					allErrs = append(allErrs, notAbove(item.Min, name, itemPath.Child("min"), request, hardPath, "requests."+string(name))...)
+					allErrs = append(allErrs, notAbove(item.DefaultRequest, name, itemPath.Child("defaultRequest"), request, hardPath, "requests."+string(name))...)
+				}
+			}
+		case corev1.LimitTypePersistentVolumeClaim:
+			allErrs = append(allErrs, validateItemOrder(item, corev1.ResourceStorage, itemPath)...)
+			if storage, ok := quota.Hard[corev1.ResourceRequestsStorage]; ok {
+				allErrs = append(allErrs, notAbove(item.Max, corev1.ResourceStorage, itemPath.Child("max"), storage, hardPath, string(corev1.ResourceRequestsStorage))...)
+				allErrs = append(allErrs, notAbove(item.Min, corev1.ResourceStorage, itemPath.Child("min"), storage, hardPath, string(corev1.ResourceRequestsStorage))...)
+			}
+		}
+	}
+
+	// Pods without resources only pass a compute quota when the LimitRange fills them in
+	for _, name := range computeResources {
+		if _, ok := quotaRequest(quota, name); ok && !hasDefaultRequest(container, name) {
+			allErrs = append(allErrs, field.Required(limitsPath.Child("limits"),
+				fmt.Sprintf("a Container default or defaultRequest for %s is required because the ResourceQuota sets requests.%s", name, name)))
+		}
+		if _, ok := quotaLimit(quota, name); ok && (container == nil || !hasKey(container.Default, name)) {
+			allErrs = append(allErrs, field.Required(limitsPath.Child("limits"),
+				fmt.Sprintf("a Container default for %s is required because the ResourceQuota sets limits.%s", name, name)))
+		}
+	}
+	return allErrs
+}
+
+// validateItemOrder checks min <= defaultRequest <= default <= max within one LimitRange item
+func validateItemOrder(item *corev1.LimitRangeItem, name corev1.ResourceName, itemPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	order := []struct {
+		key    string
+		values corev1.ResourceList
+	}{
+		{"min", item.Min},
+		{"defaultRequest", item.DefaultRequest},
+		{"default", item.Default},
+		{"max", item.Max},
+	}
+	for i := range order {
+		lower, ok := order[i].values[name]
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < len(order); j++ {
+			if upper, ok := order[j].values[name]; ok && lower.Cmp(upper) > 0 {
+				allErrs = append(allErrs, field.Invalid(itemPath.Child(order[i].key).Key(string(name)), lower.String(),
+					fmt.Sprintf("must not be greater than %s %s", order[j].key, upper.String())))
+			}
+		}
+	}
+	return allErrs
+}
+
+// notNegative reports the negative quantities of values
+func notNegative(values corev1.ResourceList, valuesPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, value := range values {
+		if value.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(valuesPath.Key(string(name)), value.String(), "must not be negative"))
+		}
+	}
+	return allErrs
+}
+
+// notAbove reports values[name] when it is greater than the quota value hard
+func notAbove(values corev1.ResourceList, name corev1.ResourceName, valuesPath *field.Path, hard resource.Quantity, hardPath *field.Path, hardKey string) field.ErrorList {
+	value, ok := values[name]
+	if !ok || value.Cmp(hard) <= 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(valuesPath.Key(string(name)), value.String(),
+		fmt.Sprintf("must not be greater than %s %s", hardPath.Key(hardKey), hard.String()))}
+}
+
+// quotaRequest returns the quota on requests of a compute resource, which may
+// be set either as requests.<name> or as the bare resource name
+func quotaRequest(quota corev1.ResourceQuotaSpec, name corev1.ResourceName) (resource.Quantity, bool) {
+	if q, ok := quota.Hard["requests."+name]; ok {
+		return q, true
+	}
+	q, ok := quota.Hard[name]
+	return q, ok
+}
+
+// quotaLimit returns the quota on limits of a compute resource
+func quotaLimit(quota corev1.ResourceQuotaSpec, name corev1.ResourceName) (resource.Quantity, bool) {
+	q, ok := quota.Hard["limits."+name]
+	return q, ok
+}
+
+// hasDefaultRequest reports whether containers without a request for name get
+// one from the LimitRange. The request defaults to the default limit.
+func hasDefaultRequest(container *corev1.LimitRangeItem, name corev1.ResourceName) bool {
+	return container != nil && (hasKey(container.DefaultRequest, name) || hasKey(container.Default, name))
+}
+
+func hasKey(values corev1.ResourceList, name corev1.ResourceName) bool {
+	_, ok := values[name]
+	return ok
+}
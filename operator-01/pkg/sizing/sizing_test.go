@@ -0,0 +1,116 @@
+package sizing
+
+import (
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespaceconfigv1 "github.com/dguyhasnoname/ohmyk8s-operator/api/v1"
+)
+
+func quantities(values map[corev1.ResourceName]string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for name, value := range values {
+		list[name] = resource.MustParse(value)
+	}
+	return list
+}
+
+func validate(limits corev1.LimitRangeSpec, quota corev1.ResourceQuotaSpec) field.ErrorList {
+	return Validate(limits, quota, field.NewPath("limits"), field.NewPath("quota"))
+}
+
+var _ = Describe("Validate", func() {
+	containerDefaults := corev1.LimitRangeItem{
+		Type:           corev1.LimitTypeContainer,
+		Max:            quantities(map[corev1.ResourceName]string{"cpu": "2", "memory": "2Gi"}),
+		Default:        quantities(map[corev1.ResourceName]string{"cpu": "500m", "memory": "512Mi"}),
+		DefaultRequest: quantities(map[corev1.ResourceName]string{"cpu": "100m", "memory": "256Mi"}),
+	}
+
+	It("accepts every shipped profile", func() {
+		files, err := filepath.Glob(filepath.Join("..", "..", "config", "profiles", "namespacesizeprofile_*.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(BeEmpty())
+		for _, file := range files {
+			f, err := os.Open(filepath.Clean(file))
+			Expect(err).ToNot(HaveOccurred())
+			profile := &namespaceconfigv1.NamespaceSizeProfile{}
+			Expect(yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(profile)).To(Succeed())
+			Expect(f.Close()).To(Succeed())
+			Expect(profile.Spec.ResourceQuota.Hard).ToNot(BeEmpty(), file)
+			Expect(ValidateProfile(profile)).To(BeEmpty(), file)
+		}
+	})
+
+	It("accepts a LimitRange that fits into the ResourceQuota", func() {
+		limits := corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{containerDefaults}}
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{
+			"requests.cpu": "4", "limits.cpu": "8", "memory": "4Gi", "limits.memory": "8Gi",
+		})}
+		Expect(validate(limits, quota)).To(BeEmpty())
+	})
+
+	It("rejects negative quantities", func() {
+		limits := corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Min:  quantities(map[corev1.ResourceName]string{"storage": "-1Gi"}),
+		}}}
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"pods": "-10"})}
+		errs := validate(limits, quota)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Field).To(Equal("quota.hard[pods]"))
+		Expect(errs[1].Field).To(Equal("limits.limits[0].min[storage]"))
+	})
+
+	It("rejects a container max above the limits quota", func() {
+		limits := corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{containerDefaults}}
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"limits.cpu": "1"})}
+		errs := validate(limits, quota)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("limits.limits[0].max[cpu]"))
+	})
+
+	It("rejects a default request above the requests quota", func() {
+		limits := corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{containerDefaults}}
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"memory": "128Mi"})}
+		errs := validate(limits, quota)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("limits.limits[0].defaultRequest[memory]"))
+	})
+
+	It("rejects a PVC max above the storage quota", func() {
+		limits := corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Max:  quantities(map[corev1.ResourceName]string{"storage": "500Gi"}),
+		}}}
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"requests.storage": "100Gi"})}
+		errs := validate(limits, quota)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("limits.limits[0].max[storage]"))
+	})
+
+	It("rejects a default above the max of the same item", func() {
+		item := *containerDefaults.DeepCopy()
+		item.Default[corev1.ResourceCPU] = resource.MustParse("4")
+		errs := validate(corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{item}}, corev1.ResourceQuotaSpec{})
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("limits.limits[0].default[cpu]"))
+	})
+
+	It("requires container defaults when compute resources are quoted", func() {
+		quota := corev1.ResourceQuotaSpec{Hard: quantities(map[corev1.ResourceName]string{"requests.cpu": "4", "limits.cpu": "8"})}
+		errs := validate(corev1.LimitRangeSpec{}, quota)
+		Expect(errs).To(HaveLen(2))
+		for _, err := range errs {
+			Expect(err.Type).To(Equal(field.ErrorTypeRequired))
+		}
+	})
+})
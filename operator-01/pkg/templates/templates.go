@@ -0,0 +1,122 @@
+// Package templates renders the manifests of NamespaceTemplates for the
+// namespace of a Namespaceconfig.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+// Data holds the fields of a Namespaceconfig the manifests are rendered with
+type Data struct {
+	// Name of the Namespaceconfig
+	Name string
+	// Namespace the manifests are applied into
+	Namespace    string
+	Environment  string
+	Abbreviation string
+	// Owner is the name of the first owner
+	Owner  string
+	Owners []namespaceconfigv2.Owner
+	// Size is the applied NamespaceSizeProfile
+	Size string
+}
+
+// DataFor returns the data of a Namespaceconfig managing namespaceName with size
+func DataFor(nc *namespaceconfigv2.Namespaceconfig, namespaceName, size string) Data {
+	data := Data{
+		Name:         nc.GetName(),
+		Namespace:    namespaceName,
+		Environment:  nc.Spec.Environment,
+		Abbreviation: nc.Spec.Abbreviation,
+		Owners:       nc.Spec.Owners,
+		Size:         size,
+	}
+	if len(nc.Spec.Owners) > 0 {
+		data.Owner = nc.Spec.Owners[0].Name
+	}
+	return data
+}
+
+// Selects reports whether a Namespaceconfig gets the manifests of a template,
+// because of its environment or of its templateSelector
+func Selects(nc *namespaceconfigv2.Namespaceconfig, tpl *namespaceconfigv2.NamespaceTemplate) (bool, error) {
+	for _, environment := range tpl.Spec.Environments {
+		if environment == nc.Spec.Environment {
+			return true, nil
+		}
+	}
+	if nc.Spec.TemplateSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(nc.Spec.TemplateSelector)
+	if err != nil {
+		return false, fmt.Errorf("spec.templateSelector is invalid: %w", err)
+	}
+	return selector.Matches(labels.Set(tpl.GetLabels())), nil
+}
+
+// funcs are the functions of the manifests. The values of a Namespaceconfig
+// come from its authors, so they are printed through quote or toYaml to keep
+// them from adding YAML of their own.
+var funcs = template.FuncMap{
+	// quote prints a value as a double-quoted YAML string
+	"quote": func(value interface{}) (string, error) {
+		quoted, err := json.Marshal(fmt.Sprint(value))
+		return string(quoted), err
+	},
+	// toYaml prints a value as YAML on a single line, in flow style
+	"toYaml": func(value interface{}) (string, error) {
+		flow, err := json.Marshal(value)
+		return string(flow), err
+	},
+}
+
+// Render renders the manifests of a template with data. Every object gets the
+// namespace of data, an object naming another namespace is an error.
+func Render(tpl *namespaceconfigv2.NamespaceTemplate, data Data) ([]*unstructured.Unstructured, error) {
+	t, err := template.New(tpl.GetName()).Option("missingkey=error").Funcs(funcs).Parse(tpl.Spec.Manifests)
+	if err != nil {
+		return nil, fmt.Errorf("NamespaceTemplate %s is invalid: %w", tpl.GetName(), err)
+	}
+	rendered := &bytes.Buffer{}
+	if err := t.Execute(rendered, data); err != nil {
+		return nil, fmt.Errorf("NamespaceTemplate %s cannot be rendered: %w", tpl.GetName(), err)
+	}
+
+	objects := []*unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(rendered, 4096)
+	for {
+		content := map[string]interface{}{}
+		if err := decoder.Decode(&content); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("NamespaceTemplate %s renders invalid YAML: %w", tpl.GetName(), err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("NamespaceTemplate %s renders an object without apiVersion, kind or metadata.name", tpl.GetName())
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != data.Namespace {
+			return nil, fmt.Errorf("NamespaceTemplate %s renders %s %s into namespace %s instead of %s",
+				tpl.GetName(), obj.GetKind(), obj.GetName(), ns, data.Namespace)
+		}
+		obj.SetNamespace(data.Namespace)
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
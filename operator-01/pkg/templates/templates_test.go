@@ -0,0 +1,84 @@
+package templates
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	namespaceconfigv2 "github.com/dguyhasnoname/ohmyk8s-operator/api/v2"
+)
+
+var _ = Describe("NamespaceTemplates", func() {
+	var (
+		nc  *namespaceconfigv2.Namespaceconfig
+		tpl *namespaceconfigv2.NamespaceTemplate
+	)
+
+	BeforeEach(func() {
+		nc = &namespaceconfigv2.Namespaceconfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: namespaceconfigv2.NamespaceconfigSpec{
+				Environment:  "dev",
+				Abbreviation: "pay",
+				Owners:       []namespaceconfigv2.Owner{{Kind: namespaceconfigv2.OwnerGroup, Name: "payments-team"}},
+			},
+		}
+		tpl = &namespaceconfigv2.NamespaceTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-info", Labels: map[string]string{"team": "payments"}},
+			Spec: namespaceconfigv2.NamespaceTemplateSpec{
+				Manifests: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: team-info
+data:
+  owner: {{ .Owner | quote }}
+  owners: {{ toYaml .Owners | quote }}
+  size: {{ .Size | quote }}
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: {{ .Abbreviation }}-deployer
+`,
+			},
+		}
+	})
+
+	It("selects templates by environment or by label", func() {
+		Expect(Selects(nc, tpl)).To(BeFalse())
+		tpl.Spec.Environments = []string{"dev", "prod"}
+		Expect(Selects(nc, tpl)).To(BeTrue())
+
+		tpl.Spec.Environments = nil
+		nc.Spec.TemplateSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+		Expect(Selects(nc, tpl)).To(BeTrue())
+	})
+
+	It("renders the objects into the namespace", func() {
+		objects, err := Render(tpl, DataFor(nc, "pay-dev", "s"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].GetNamespace()).To(Equal("pay-dev"))
+		Expect(objects[0].Object["data"]).To(HaveKeyWithValue("owner", "payments-team"))
+		Expect(objects[1].GetName()).To(Equal("pay-deployer"))
+		Expect(objects[0].Object["data"]).To(HaveKeyWithValue("owners", `[{"kind":"Group","name":"payments-team"}]`))
+	})
+
+	It("keeps quoted values from adding objects", func() {
+		nc.Spec.Owners[0].Name = "x\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: RoleBinding\nmetadata:\n  name: pwned"
+		objects, err := Render(tpl, DataFor(nc, "pay-dev", "s"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].Object["data"]).To(HaveKeyWithValue("owner", nc.Spec.Owners[0].Name))
+	})
+
+	It("rejects unknown fields and other namespaces", func() {
+		tpl.Spec.Manifests = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Team }}\n"
+		_, err := Render(tpl, DataFor(nc, "pay-dev", "s"))
+		Expect(err).To(MatchError(ContainSubstring("cannot be rendered")))
+
+		tpl.Spec.Manifests = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: info\n  namespace: kube-system\n"
+		_, err = Render(tpl, DataFor(nc, "pay-dev", "s"))
+		Expect(err).To(MatchError(ContainSubstring("into namespace kube-system")))
+	})
+})
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// invalidLabelValue matches the characters not allowed in a label value
+var invalidLabelValue = regexp.MustCompile(`[^-_.A-Za-z0-9]+`)
+
+// LabelValue turns s into a valid label value, such as system-serviceaccount-ci-deployer
+// for the user system:serviceaccount:ci:deployer
+func LabelValue(s string) string {
+	value := invalidLabelValue.ReplaceAllString(s, "-")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+// reservedKeys are the labels and annotations of a namespace set by the
+// operator or by Kubernetes
+var reservedKeys = map[string]bool{
+	"owner":                       true,
+	"env":                         true,
+	"managed-by":                  true,
+	"kubernetes.io/metadata.name": true,
+}
+
+// reservedPrefixes start the reserved keys of the operator and of Pod Security Admission
+var reservedPrefixes = []string{"namespaceconfig.myoperator.io/", "pod-security.kubernetes.io/"}
+
+// ReservedKey reports whether key is a label or annotation of a namespace that
+// only the operator or Kubernetes may set
+func ReservedKey(key string) bool {
+	if reservedKeys[key] {
+		return true
+	}
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}